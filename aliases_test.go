@@ -0,0 +1,47 @@
+//go:build go1.24
+
+package gos2tsi
+
+import (
+	"testing"
+
+	"github.com/N4r35h/gos2tsi/examplestructs"
+)
+
+func TestGenericTypeAliases(t *testing.T) {
+	ps := c.ParseStruct(examplestructs.StructWithGenericAliases{})
+	op := c.GetStructAsInterfaceString(ps)
+	expected := `export interface StructWithGenericAliases {
+tags: {[key: string]: {}}
+users: Page<User>
+}`
+	if op != expected {
+		t.Error(expected)
+		t.Error(op)
+	}
+}
+
+func TestEmittedGenericTypeAliases(t *testing.T) {
+	ac := New()
+	ac.EmitTypeAliases = true
+	ps := ac.ParseStruct(examplestructs.StructWithGenericAliases{})
+	op := ac.GetStructAsInterfaceString(ps)
+	expected := `export interface StructWithGenericAliases {
+tags: Set<string>
+users: PageOf<User>
+}`
+	if op != expected {
+		t.Error(expected)
+		t.Error(op)
+	}
+	for aliasID, expected := range map[string]string{
+		"Set":    `export type Set<T> = {[key: string]: {}}`,
+		"PageOf": `export type PageOf<T> = Page<T>`,
+	} {
+		op := ac.GetTypeAliasString(ac.TypeAliases["github.com/N4r35h/gos2tsi/examplestructs."+aliasID])
+		if op != expected {
+			t.Error(expected)
+			t.Error(op)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package gos2tsi
+
+import (
+	"go/types"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// GetClassString returns a TS class for a struct, its properties default to the go zero values
+// and the constructor assigns the properties it's given, ex:
+// export class User { id = 0; name = ""; constructor(init?: Partial<User>) {...} }
+func (c *Converter) GetClassString(ps ParsedStruct) string {
+	var sb strings.Builder
+	c.WriteClass(&sb, ps)
+	return sb.String()
+}
+
+// WriteClass writes the class GetClassString returns to w
+func (c *Converter) WriteClass(w io.Writer, ps ParsedStruct) (int64, error) {
+	e := newEmitter(w)
+	c.emitClass(e, ps)
+	return e.flush()
+}
+
+// WriteClasses writes the classes of every required struct to w
+func (c *Converter) WriteClasses(w io.Writer) (int64, error) {
+	e := newEmitter(w)
+	for _, id := range sortedKeys(c.Structs) {
+		if ps := c.Structs[id]; ps.Required {
+			c.emitClass(e, ps)
+			e.line()
+		}
+	}
+	return e.flush()
+}
+
+func (c *Converter) emitClass(e *emitter, ps ParsedStruct) {
+	if ps.Name == "" {
+		return
+	}
+	if doc := c.Docs[removeGenericsPartFromStructName(ps.Name)]; doc != "" {
+		c.emitTSComment(e, doc)
+		e.str("\n")
+	}
+	name := c.getStructTypeName(ps)
+	if typeParams := c.getTypeParamNames(ps); len(typeParams) > 0 {
+		name += "<" + strings.Join(typeParams, ", ") + ">"
+	}
+	e.str(c.getValueDeclarationPrefix("class"))
+	e.str(c.getInterfaceName(ps))
+	e.str(" {")
+	for _, pf := range c.flattenEmbeddedFields(ps.Fields) {
+		e.str("\n" + c.Indent + c.getClassProperty(ps, pf) + c.getStatementEnd())
+	}
+	e.str("\n\n" + c.Indent + "constructor(init?: Partial<" + name + ">) {\n")
+	e.str(c.Indent + c.Indent + "Object.assign(this, init)" + c.getStatementEnd() + "\n")
+	e.str(c.Indent + "}\n}")
+	if c.Style.TrailingNewline {
+		e.str("\n")
+	}
+}
+
+// getClassProperty returns the declaration of the property of a field of ps, initialized to the
+// zero value of its go type, the type annotation is left out when TS infers it from the zero value
+func (c *Converter) getClassProperty(ps ParsedStruct, pf ParsedField) string {
+	property := c.getPropertyName(pf.TSName)
+	if pf.Readonly || c.Style.Readonly {
+		property = "readonly " + property
+	}
+	if pf.Optional {
+		property += "?"
+	}
+	_, isDiscriminator := c.getDiscriminator(ps, pf)
+	pf = c.narrowDiscriminator(ps, pf)
+	TSType := c.postProcessTSTypeName(pf.TSType)
+	for i := 0; i < pf.IsSlice; i++ {
+		TSType = c.sliceOf(TSType)
+	}
+	var zeroValue string
+	var ok bool
+	if isDiscriminator {
+		// literal types are their own zero value
+		zeroValue, ok = TSType, true
+	} else if hasTypeOverride(pf) {
+		zeroValue, ok = c.getTSTypeZeroValue(TSType)
+	} else {
+		zeroValue, ok = c.getZeroValue(pf.Var.Type(), false, 0)
+	}
+	if !ok {
+		if pf.Optional {
+			return property + ": " + TSType
+		}
+		// type parameters and inline object types have no zero value to initialize them with
+		return property + "!: " + TSType
+	}
+	if zeroValue == "null" && TSType != "any" && TSType != "unknown" && !strings.HasSuffix(TSType, " | null") {
+		TSType += " | null"
+	}
+	// readonly properties would be inferred as literal types
+	if isPrimitiveTSType(TSType) && !pf.Optional && !pf.Readonly && !c.Style.Readonly {
+		return property + " = " + zeroValue
+	}
+	return property + ": " + TSType + " = " + zeroValue
+}
+
+// getZeroValue returns the TS expression of the JSON encoding of the zero value of t, ok is
+// false for types that don't have one (inline structs, type parameters of classes), factory
+// creates structs and type parameters via their factories, whose parameters are named after
+// depth, and follows FactoryNilSlices, otherwise structs are created with their class constructor
+func (c *Converter) getZeroValue(t types.Type, factory bool, depth int) (string, bool) {
+	if tsType, ok := c.lookupTypeMapping(t); ok {
+		if isTime(t) && tsType == "string" {
+			return c.quote(zeroTime), true
+		}
+		return c.getTSTypeZeroValue(tsType)
+	}
+	switch item := t.(type) {
+	case *types.Pointer, *types.Interface, *types.Signature, *types.Chan:
+		return "null", true
+	case *types.Slice:
+		if factory && c.FactoryNilSlices == NilSlicesAsNull {
+			return "null", true
+		}
+		if isByteSlice(item) {
+			return c.quote(""), true
+		}
+		return "[]", true
+	case *types.Map:
+		if factory && c.FactoryNilSlices == NilSlicesAsNull {
+			return "null", true
+		}
+		return "{}", true
+	case *types.Array:
+		if basic, ok := item.Elem().Underlying().(*types.Basic); ok && basic.Kind() == types.Byte && c.ByteArraysAsString {
+			return c.quote(""), true
+		}
+		elemZeroValue, ok := c.getZeroValue(item.Elem(), factory, depth)
+		if !ok {
+			return "", false
+		}
+		if c.MaxTupleLength > 0 && item.Len() > c.MaxTupleLength {
+			return "Array.from({length: " + strconv.FormatInt(item.Len(), 10) + "}, () => " + elemZeroValue + ")", true
+		}
+		elems := make([]string, item.Len())
+		for i := range elems {
+			elems[i] = elemZeroValue
+		}
+		return "[" + strings.Join(elems, ", ") + "]", true
+	case *types.Named:
+		switch item.Underlying().(type) {
+		case *types.Struct:
+			if !factory {
+				return "new " + c.tsType(item) + "()", true
+			}
+			args := []string{}
+			for i := 0; i < item.TypeArgs().Len(); i++ {
+				args = append(args, c.getFactoryFunc(item.TypeArgs().At(i), depth))
+			}
+			return getFactoryName(c.getObjectTypeName(item.Obj())) + "(" + strings.Join(args, ", ") + ")", true
+		case *types.Interface:
+			return "null", true
+		case *types.Basic:
+			if c.isEnum(item) {
+				return c.getEnumZeroValue(item)
+			}
+		}
+		return c.getZeroValue(item.Underlying(), factory, depth)
+	case *types.TypeParam:
+		if factory {
+			return getFactoryName(item.Obj().Name()) + "()", true
+		}
+	case *types.Alias:
+		return c.getZeroValue(types.Unalias(item), factory, depth)
+	case *types.Basic:
+		return c.getBasicZeroValue(item)
+	}
+	return "", false
+}
+
+func (c *Converter) getBasicZeroValue(basic *types.Basic) (string, bool) {
+	switch {
+	case basic.Info()&types.IsString != 0:
+		return c.quote(""), true
+	case basic.Info()&types.IsNumeric != 0:
+		return "0", true
+	case basic.Info()&types.IsBoolean != 0:
+		return "false", true
+	case basic.Kind() == types.UnsafePointer:
+		return "null", true
+	}
+	return "", false
+}
+
+// getTSTypeZeroValue returns the zero value of a TS type given as a string, only primitives,
+// arrays and nullable types have one
+func (c *Converter) getTSTypeZeroValue(tsType string) (string, bool) {
+	switch {
+	case tsType == "string":
+		return c.quote(""), true
+	case tsType == "number":
+		return "0", true
+	case tsType == "boolean":
+		return "false", true
+	case tsType == "any" || tsType == "unknown" || strings.HasSuffix(tsType, " | null"):
+		return "null", true
+	case strings.HasSuffix(tsType, "[]") || strings.HasPrefix(tsType, "ReadonlyArray<"):
+		return "[]", true
+	}
+	return "", false
+}
+
+// zeroTime is the JSON encoding of the zero time.Time
+const zeroTime = "0001-01-01T00:00:00Z"
+
+func isPrimitiveTSType(tsType string) bool {
+	return tsType == "string" || tsType == "number" || tsType == "boolean"
+}
@@ -0,0 +1,41 @@
+// Command gos2tsi generates the TS files described by a gos2tsi.yaml or gos2tsi.json config file,
+// the targets to generate can be named, all of them are by default:
+//
+//	gos2tsi [-config path] [target ...]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/N4r35h/gos2tsi"
+)
+
+// configFiles are looked up in the current directory when no config file is given
+var configFiles = []string{"gos2tsi.yaml", "gos2tsi.yml", "gos2tsi.json"}
+
+func main() {
+	configPath := flag.String("config", "", "path of the config file, gos2tsi.yaml, gos2tsi.yml or gos2tsi.json by default")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: gos2tsi [-config path] [target ...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *configPath == "" {
+		for _, name := range configFiles {
+			if _, err := os.Stat(name); err == nil {
+				*configPath = name
+				break
+			}
+		}
+	}
+	if *configPath == "" {
+		fmt.Fprintln(os.Stderr, "gos2tsi: no gos2tsi.yaml, gos2tsi.yml or gos2tsi.json in the current directory")
+		os.Exit(2)
+	}
+	if err := gos2tsi.RunConfig(*configPath, flag.Args()...); err != nil {
+		fmt.Fprintln(os.Stderr, "gos2tsi:", err)
+		os.Exit(1)
+	}
+}
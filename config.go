@@ -0,0 +1,185 @@
+package gos2tsi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config describes the TS files to generate, read from a gos2tsi.yaml or gos2tsi.json file by
+// LoadConfig, ex:
+//
+//	targets:
+//	  - name: public
+//	    output: web/src/api.ts
+//	    packages: [./api/...]
+//	    style: {terminator: ";"}
+type Config struct {
+	// Dir is the directory packages are loaded from, relative to the config file which is the default
+	Dir     string         `json:"dir" yaml:"dir"`
+	Targets []TargetConfig `json:"targets" yaml:"targets"`
+}
+
+// TargetConfig describes a generated TS file, the types it starts from and the converter options
+type TargetConfig struct {
+	Name string `json:"name" yaml:"name"`
+	// Output is the path of the TS file, relative to the config file
+	Output string `json:"output" yaml:"output"`
+	// Packages are package patterns whose //gos2tsi:export types are generated, ex: ./api/...
+	Packages []string `json:"packages" yaml:"packages"`
+	// Types are fully qualified types to generate, ex: example.com/api.User or
+	// example.com/api.Page[example.com/api.User]
+	Types  []string    `json:"types" yaml:"types"`
+	Indent string      `json:"indent" yaml:"indent"`
+	Style  OutputStyle `json:"style" yaml:"style"`
+	// TypeMappings are added to the default ones, see MapType
+	TypeMappings map[string]string `json:"type_mappings" yaml:"type_mappings"`
+	// FieldNaming renames the fields without a name in their tags: camel, snake or "" for the go name
+	FieldNaming        string   `json:"field_naming" yaml:"field_naming"`
+	TagKeys            []string `json:"tag_keys" yaml:"tag_keys"`
+	NullablePointers   bool     `json:"nullable_pointers" yaml:"nullable_pointers"`
+	MapsAsRecord       bool     `json:"maps_as_record" yaml:"maps_as_record"`
+	EmitTypeAliases    bool     `json:"emit_type_aliases" yaml:"emit_type_aliases"`
+	HoistInlineStructs bool     `json:"hoist_inline_structs" yaml:"hoist_inline_structs"`
+	IncludeUnexported  bool     `json:"include_unexported" yaml:"include_unexported"`
+	// Strict fails the target when some fields have types that couldn't be converted
+	Strict bool `json:"strict" yaml:"strict"`
+	// TypeGuards and Factories add the type guards and the factories of the structs to the output
+	TypeGuards bool `json:"type_guards" yaml:"type_guards"`
+	Factories  bool `json:"factories" yaml:"factories"`
+}
+
+// LoadConfig reads a config file, .json files are decoded as JSON and any other as YAML, unknown
+// keys are reported as errors
+func LoadConfig(path string) (Config, error) {
+	var config Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&config)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&config)
+	}
+	if err != nil {
+		return config, fmt.Errorf("%s: %w", path, err)
+	}
+	for i, target := range config.Targets {
+		if target.Output == "" {
+			return config, fmt.Errorf("%s: target %d (%s) has no output", path, i, target.Name)
+		}
+	}
+	return config, nil
+}
+
+// RunConfig generates the TS files of the targets of the config file at path, only the ones
+// named if any are given, each target gets its own Converter
+func RunConfig(path string, targets ...string) error {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	configDir := filepath.Dir(path)
+	dir := resolvePath(configDir, config.Dir)
+	for _, name := range targets {
+		if !slices.ContainsFunc(config.Targets, func(target TargetConfig) bool { return target.Name == name }) {
+			return fmt.Errorf("%s: no target named %s", path, name)
+		}
+	}
+	for _, target := range config.Targets {
+		if len(targets) > 0 && !slices.Contains(targets, target.Name) {
+			continue
+		}
+		if err := target.run(dir, resolvePath(configDir, target.Output)); err != nil {
+			return fmt.Errorf("target %s: %w", target.Name, err)
+		}
+	}
+	return nil
+}
+
+// NewConverter returns a Converter configured as the target with its types parsed, packages are
+// loaded from dir
+func (t TargetConfig) NewConverter(dir string) (*Converter, error) {
+	c := New()
+	c.Dir = dir
+	c.Indent = t.Indent
+	c.Style = t.Style
+	for goType, tsType := range t.TypeMappings {
+		c.MapType(goType, tsType)
+	}
+	switch t.FieldNaming {
+	case "":
+	case "camel":
+		c.FieldNaming = CamelCase
+	case "snake":
+		c.FieldNaming = SnakeCase
+	default:
+		return nil, fmt.Errorf("unknown field naming %s, expected camel or snake", t.FieldNaming)
+	}
+	if len(t.TagKeys) > 0 {
+		c.TagKeys = t.TagKeys
+	}
+	c.NullablePointers = t.NullablePointers
+	c.MapsAsRecord = t.MapsAsRecord
+	c.EmitTypeAliases = t.EmitTypeAliases
+	c.HoistInlineStructs = t.HoistInlineStructs
+	c.IncludeUnexported = t.IncludeUnexported
+	c.Strict = t.Strict
+	if len(t.Packages) > 0 {
+		if _, err := c.ParseExportedTypes(t.Packages...); err != nil {
+			return nil, err
+		}
+	}
+	for _, goType := range t.Types {
+		resolved := c.goTypeFromTypeString(goType)
+		if resolved == nil {
+			return nil, fmt.Errorf("type %s not found", goType)
+		}
+		c.addRoot(resolved)
+	}
+	return c, c.Err()
+}
+
+// run writes the declarations of the target, followed by the type guards and factories it asks
+// for, to the file at output
+func (t TargetConfig) run(dir, output string) error {
+	c, err := t.NewConverter(dir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	_, err = c.WriteTo(f)
+	if err == nil && t.TypeGuards {
+		_, err = c.WriteTypeGuards(f)
+	}
+	if err == nil && t.Factories {
+		_, err = c.WriteFactories(f)
+	}
+	return errors.Join(err, f.Close())
+}
+
+// resolvePath resolves a path of the config file, relative ones are relative to the config file
+func resolvePath(configDir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(configDir, path)
+}
@@ -1,9 +1,20 @@
 package gos2tsi
 
 import (
+	"encoding/json"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/N4r35h/gos2tsi/examplestructs"
+	"github.com/N4r35h/gos2tsi/exunionpkg"
+	"github.com/N4r35h/gos2tsi/exunionpkg2"
 )
 
 var c *Converter = New()
@@ -190,7 +201,7 @@ array_int16: number[]
 array_int32: number[]
 array_int64: number[]
 array_uint: number[]
-array_uint8: number[]
+array_uint8: string
 array_uint16: number[]
 array_uint32: number[]
 array_uint64: number[]
@@ -215,7 +226,7 @@ map_string_int: {[key: string]: number}
 map_string_float: {[key: string]: number}
 map_string_interface: {[key: string]: any}
 map_string_any: {[key: string]: any}
-map_string_primitive_struct: PrimitiveStruct
+map_string_primitive_struct: {[key: string]: PrimitiveStruct}
 map_of_maps_of_maps: {[key: string]: {[key: string]: {[key: string]: string}}}
 map_of_arrays_of_maps: {[key: string]: {[key: string]: string}[]}
 }`
@@ -306,7 +317,9 @@ func TestStructWithInlineStruct(t *testing.T) {
 
 	ps := c.ParseStruct(examplestructs.StructWithInlineStruct{})
 	op := c.GetStructAsInterfaceString(ps)
-	expected := ``
+	expected := `export interface StructWithInlineStruct {
+InlineStructData: {test: string}
+}`
 	if op != expected {
 		t.Errorf(expected)
 		t.Errorf(op)
@@ -340,6 +353,20 @@ optional_field?: string
 		t.Errorf(expected)
 		t.Errorf(op)
 	}
+
+	// fields built by hand without Optional set get it from their tags
+	for tag, expected := range map[string]string{
+		`json:"name,omitempty"`:                     "\nname?: string",
+		`json:"name" optional:"true"`:               "\nname?: string",
+		`json:"name,omitempty" ts:"optional=false"`: "\nname: string",
+		`json:"name"`:                               "\nname: string",
+	} {
+		pf := ParsedField{Var: types.NewField(token.NoPos, nil, "Name", types.Typ[types.String], false), Tag: tag, TSName: "name", TSType: "string"}
+		if op := c.GetFieldAsString(pf); op != expected {
+			t.Errorf(expected)
+			t.Errorf(op)
+		}
+	}
 }
 
 func TestPointerFields(t *testing.T) {
@@ -348,7 +375,7 @@ func TestPointerFields(t *testing.T) {
 	expected := `export interface StructWithPointers {
 int_field: number
 bool_field: boolean
-array_field: []string
+array_field: string[]
 entity_x: StructWithOptionalField
 }`
 	if op != expected {
@@ -356,3 +383,1545 @@ entity_x: StructWithOptionalField
 		t.Errorf(op)
 	}
 }
+
+func TestTypeMappings(t *testing.T) {
+	mc := New()
+	mc.MapType("time.Time", "string")
+	mc.MapType("github.com/N4r35h/gos2tsi/examplestructs.SingleGenericStruct[github.com/N4r35h/gos2tsi/examplestructs.SimpleStruct]", "SimpleStructPage")
+	mc.TypeMapper = func(t types.Type) (string, bool) {
+		if named, ok := t.(*types.Named); ok && named.Obj().Name() == "SimpleStructPkg2" {
+			return "Record<string, string>", true
+		}
+		return "", false
+	}
+	ps := mc.ParseStruct(examplestructs.StructWithMappedTypes{})
+	op := mc.GetStructAsInterfaceString(ps)
+	expected := `export interface StructWithMappedTypes {
+created_at: string
+history: string[]
+deadlines: {[key: string]: string}
+page: SimpleStructPage
+external: Record<string, string>
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	if _, exists := mc.Structs["time.Time"]; exists {
+		t.Errorf("mapped types must not be parsed as structs")
+	}
+	if _, exists := New().TypeMappings["time.Time"]; exists {
+		t.Errorf("type mappings must not leak between converters")
+	}
+}
+
+func TestArraysAsTuples(t *testing.T) {
+	ps := c.ParseStruct(examplestructs.StructWithArrays{})
+	op := c.GetStructAsInterfaceString(ps)
+	expected := `export interface StructWithArrays {
+coords: [number, number]
+matrix: [[number, number, number], [number, number, number], [number, number, number]]
+points: [number, number][]
+corners: [SimpleStruct, SimpleStruct]
+checksum: [number, number, number, number]
+samples: [number, number, number, number, number, number]
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+}
+
+func TestArraysWithMaxTupleLength(t *testing.T) {
+	ac := New()
+	ac.MaxTupleLength = 4
+	ac.ByteArraysAsString = true
+	ps := ac.ParseStruct(examplestructs.StructWithArrays{})
+	op := ac.GetStructAsInterfaceString(ps)
+	expected := `export interface StructWithArrays {
+coords: [number, number]
+matrix: [[number, number, number], [number, number, number], [number, number, number]]
+points: [number, number][]
+corners: [SimpleStruct, SimpleStruct]
+checksum: string
+samples: number[]
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+}
+
+func TestMapKeys(t *testing.T) {
+	ps := c.ParseStruct(examplestructs.StructWithMapKeys{})
+	op := c.GetStructAsInterfaceString(ps)
+	expected := `export interface StructWithMapKeys {
+int_keys: {[key: string]: string}
+uint_keys: {[key: string]: boolean}
+named_int_keys: {[key: string]: string}
+labels: {[key: string]: number}
+by_status: {[key in OrderStatus]?: number}
+by_point: {[key: string]: string}
+nested_maps: {[key: string]: {[key in OrderStatus]?: string}}
+slice_values: {[key: string]: number[]}
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	op = c.GetEnumAsTypeString(c.Enums["github.com/N4r35h/gos2tsi/examplestructs.OrderStatus"])
+	expected = `export type OrderStatus = "pending" | "paid"`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	if !c.Enums["github.com/N4r35h/gos2tsi/examplestructs.OrderStatus"].Required {
+		t.Errorf("enums used as map keys must be marked as required")
+	}
+}
+
+func TestEnums(t *testing.T) {
+	ec := New()
+	ps := ec.ParseStruct(examplestructs.StructWithEnums{})
+	var sb strings.Builder
+	ec.WriteTo(&sb)
+	ec.WriteClass(&sb, ps)
+	op := sb.String()
+	// only the types marked with //gos2tsi:enum are, unless they are bitflags
+	expected := `export interface StructWithEnums {
+status: OrderStatus
+permissions: number
+timeout: number
+month: number
+mode: number
+}
+export type OrderStatus = "pending" | "paid"
+export class StructWithEnums {
+status: OrderStatus = "" as unknown as OrderStatus
+permissions = 0
+timeout = 0
+month = 0
+mode = 0
+
+constructor(init?: Partial<StructWithEnums>) {
+Object.assign(this, init)
+}
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	for _, property := range ec.GetOpenAPIComponents().Schemas["StructWithEnums"].Properties {
+		enum := property.Schema.Enum
+		if property.Name == "status" && (len(enum) != 2 || enum[0] != "pending" || enum[1] != "paid") {
+			t.Errorf("expected the values of OrderStatus in the order they are declared in, got %v", enum)
+		}
+		if property.Name != "status" && enum != nil {
+			t.Errorf("expected no enum for %s, got %v", property.Name, enum)
+		}
+	}
+}
+
+func TestMapsAsRecord(t *testing.T) {
+	rc := New()
+	rc.MapsAsRecord = true
+	ps := rc.ParseStruct(examplestructs.StructWithMapKeys{})
+	op := rc.GetStructAsInterfaceString(ps)
+	expected := `export interface StructWithMapKeys {
+int_keys: Record<string, string>
+uint_keys: Record<string, boolean>
+named_int_keys: Record<string, string>
+labels: Record<string, number>
+by_status: Partial<Record<OrderStatus, number>>
+by_point: Record<string, string>
+nested_maps: Record<string, Partial<Record<OrderStatus, string>>>
+slice_values: Record<string, number[]>
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+}
+
+func TestRecursiveStruct(t *testing.T) {
+	nc := New()
+	nc.NullablePointers = true
+	ps := nc.ParseStruct(examplestructs.Node{})
+	op := nc.GetStructAsInterfaceString(ps)
+	expected := `export interface Node {
+name: string
+children: Node[]
+siblings: (Node | null)[]
+parent: Node | null
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	if !ps.Fields[1].RefStruct.Recursive || len(ps.Fields[1].RefStruct.Fields) != 0 {
+		t.Errorf("self reference must be marked as recursive")
+	}
+	if len(nc.Structs["github.com/N4r35h/gos2tsi/examplestructs.Node"].Fields) != 4 {
+		t.Errorf("recursive struct must be fully parsed")
+	}
+}
+
+func TestMutuallyRecursiveStructs(t *testing.T) {
+	ps := c.ParseStruct(examplestructs.Employee{})
+	op := c.GetStructAsInterfaceString(ps)
+	expected := `export interface Employee {
+name: string
+team: Team
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	team := ps.Fields[1].RefStruct
+	op = c.GetStructAsInterfaceString(team)
+	expected = `export interface Team {
+lead: Employee
+members: Employee[]
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	if !c.Structs["github.com/N4r35h/gos2tsi/examplestructs.Team"].Required {
+		t.Errorf("referenced struct must be marked as required")
+	}
+}
+
+func TestNestedInlineStructs(t *testing.T) {
+	ps := c.ParseStruct(examplestructs.StructWithNestedInlineStructs{})
+	op := c.GetStructAsInterfaceString(ps)
+	expected := `export interface StructWithNestedInlineStructs {
+meta: {count: number; cursor?: string; window: {from: number; to: number}}
+items: {id: number; owner?: SimpleStruct}[]
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+}
+
+func TestHoistedInlineStructs(t *testing.T) {
+	hc := New()
+	hc.HoistInlineStructs = true
+	ps := hc.ParseStruct(examplestructs.StructWithNestedInlineStructs{})
+	op := hc.GetStructAsInterfaceString(ps)
+	expected := `export interface StructWithNestedInlineStructs {
+meta: StructWithNestedInlineStructs_Meta
+items: StructWithNestedInlineStructs_Items[]
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	op = hc.GetStructAsInterfaceString(hc.Structs["github.com/N4r35h/gos2tsi/examplestructs.StructWithNestedInlineStructs_Meta"])
+	expected = `export interface StructWithNestedInlineStructs_Meta {
+count: number
+cursor?: string
+window: StructWithNestedInlineStructs_Meta_Window
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	op = hc.GetStructAsInterfaceString(hc.Structs["github.com/N4r35h/gos2tsi/examplestructs.StructWithNestedInlineStructs_Meta_Window"])
+	expected = `export interface StructWithNestedInlineStructs_Meta_Window {
+from: number
+to: number
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+}
+
+func TestGenericConstraints(t *testing.T) {
+	ps := c.ParseStruct(examplestructs.ConstrainedGenericStruct[string, int, float64, string, int, int64, examplestructs.SimpleStruct, time.Time]{})
+	op := c.GetStructAsInterfaceString(ps)
+	expected := `export interface ConstrainedGenericStruct<T, K, N extends number, S extends string, I extends number | string, M extends number, P extends SimpleStruct, E> {
+items: T[]
+keys: K[]
+total: N
+label: S
+id: I
+measure: M
+payload: P
+stringer: E
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+}
+
+func TestGenericInstantiationAlias(t *testing.T) {
+	ps := c.ParseStruct(examplestructs.MultiGenericStruct[examplestructs.SimpleStruct, examplestructs.SimpleStruct1]{})
+	op := c.GetInstantiationAliasString(ps)
+	expected := `export type MultiGenericStruct_SimpleStruct_SimpleStruct1 = MultiGenericStruct<SimpleStruct, SimpleStruct1>`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	if _, exists := c.Instantiations["MultiGenericStruct_SimpleStruct_SimpleStruct1"]; !exists {
+		t.Errorf("instantiation must be recorded on the converter")
+	}
+
+	ps = c.ParseStruct(examplestructs.MultiGenericStruct[[]int, map[string]examplestructs.SingleGenericStruct[examplestructs.SimpleStruct]]{})
+	op = c.GetInstantiationAliasString(ps)
+	expected = `export type MultiGenericStruct_numberArray_key_string_SingleGenericStruct_SimpleStruct = MultiGenericStruct<number[], {[key: string]: SingleGenericStruct<SimpleStruct>}>`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+
+	if op := c.GetInstantiationAliasString(c.ParseStruct(examplestructs.SimpleStruct{})); op != "" {
+		t.Errorf("non generic structs must not get an alias, got %s", op)
+	}
+}
+
+func TestGenericInstantiationNamer(t *testing.T) {
+	nc := New()
+	nc.InstantiationNamer = func(name string, typeArgs []string) string {
+		return name + "Of" + strings.Join(typeArgs, "And")
+	}
+	ps := nc.ParseStruct(examplestructs.MultiGenericStruct[examplestructs.SimpleStruct, examplestructs.SimpleStruct1]{})
+	op := nc.GetInstantiationAliasString(ps)
+	expected := `export type MultiGenericStructOfSimpleStructAndSimpleStruct1 = MultiGenericStruct<SimpleStruct, SimpleStruct1>`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+}
+
+func TestGenericInstantiationFields(t *testing.T) {
+	ps := c.ParseStruct(examplestructs.StructWithGenericFields[string, int]{})
+	op := c.GetStructAsInterfaceString(ps)
+	expected := `export interface StructWithGenericFields<K, V> {
+items: Page<User>
+cache: {[key: string]: Result<Order, unknown>}
+nested: Wrapper<Pair<K, V>[]>
+pages: Page<Pair<string, User>>[]
+tree: Tree<V>
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	if op := c.GetInstantiationAliasString(ps.Fields[0].RefStruct); op != `export type Page_User = Page<User>` {
+		t.Errorf(op)
+	}
+	if _, exists := c.Instantiations["Page_Pair_string_User"]; !exists {
+		t.Errorf("nested instantiation must be recorded on the converter")
+	}
+	for _, name := range []string{"Page", "Result", "Pair", "Wrapper", "User", "Order"} {
+		if !c.Structs["github.com/N4r35h/gos2tsi/examplestructs."+name].Required {
+			t.Errorf("%s must be marked as required", name)
+		}
+	}
+
+	op = c.GetStructAsInterfaceString(c.Structs["github.com/N4r35h/gos2tsi/examplestructs.Tree"])
+	expected = `export interface Tree<T> {
+value: T
+children: Tree<T>[]
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+}
+
+func TestTypeAliases(t *testing.T) {
+	ps := c.ParseStruct(examplestructs.StructWithAliases{})
+	op := c.GetStructAsInterfaceString(ps)
+	expected := `export interface StructWithAliases {
+owner: User
+remote: SimpleStructPkg2
+labels: {[key: string]: string}
+ids: number[]
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	if _, exists := c.Structs["github.com/N4r35h/gos2tsi/examplestructs.UserAlias"]; exists {
+		t.Errorf("aliases must not be parsed as structs of their own")
+	}
+}
+
+func TestEmittedTypeAliases(t *testing.T) {
+	ac := New()
+	ac.EmitTypeAliases = true
+	ps := ac.ParseStruct(examplestructs.StructWithAliases{})
+	op := ac.GetStructAsInterfaceString(ps)
+	expected := `export interface StructWithAliases {
+owner: UserAlias
+remote: RemoteStruct
+labels: Labels
+ids: IDList
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	for aliasID, expected := range map[string]string{
+		"UserAlias":    `export type UserAlias = User`,
+		"RemoteStruct": `export type RemoteStruct = SimpleStructPkg2`,
+		"Labels":       `export type Labels = {[key: string]: string}`,
+		"IDList":       `export type IDList = number[]`,
+	} {
+		op := ac.GetTypeAliasString(ac.TypeAliases["github.com/N4r35h/gos2tsi/examplestructs."+aliasID])
+		if op != expected {
+			t.Errorf(expected)
+			t.Errorf(op)
+		}
+	}
+	// aliases of the package the struct doesn't refer to aren't output
+	var sb strings.Builder
+	ac.WriteTo(&sb)
+	op = sb.String()
+	for _, aliasID := range []string{"Set", "PageOf"} {
+		if declaration := ac.GetTypeAliasString(ac.TypeAliases["github.com/N4r35h/gos2tsi/examplestructs."+aliasID]); strings.Contains(op, declaration) {
+			t.Errorf("unreachable alias %s in %s", declaration, op)
+		}
+	}
+	if !strings.Contains(op, "export type IDList = number[]\n") {
+		t.Errorf("missing alias IDList in %s", op)
+	}
+}
+
+func TestInterfaceUnion(t *testing.T) {
+	ps := c.ParseStruct(examplestructs.EventEnvelope{})
+	op := c.GetStructAsInterfaceString(ps)
+	expected := `export interface EventEnvelope {
+id: string
+payload: Event
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	op = c.GetUnionAsTypeString(c.Unions["github.com/N4r35h/gos2tsi/examplestructs.Event"])
+	expected = `export type Event = UserCreated | OrderPaid`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	op = c.GetStructAsInterfaceString(c.Structs["github.com/N4r35h/gos2tsi/examplestructs.UserCreated"])
+	expected = `export interface UserCreated {
+type: string
+user: User
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	op = c.GetStructAsInterfaceString(c.Structs["github.com/N4r35h/gos2tsi/examplestructs.OrderPaid"])
+	expected = `export interface OrderPaid {
+type: "order_paid"
+amount: number
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	if !c.Structs["github.com/N4r35h/gos2tsi/examplestructs.OrderPaid"].Required {
+		t.Errorf("union members must be marked as required")
+	}
+}
+
+func TestInterfaceUnionOption(t *testing.T) {
+	uc := New()
+	uc.UnionInterfaces["github.com/N4r35h/gos2tsi/examplestructs.Shape"] = "kind"
+	ps := uc.ParseStruct(examplestructs.Drawing{})
+	op := uc.GetStructAsInterfaceString(ps)
+	expected := `export interface Drawing {
+shapes: Shape[]
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	op = uc.GetUnionAsTypeString(uc.Unions["github.com/N4r35h/gos2tsi/examplestructs.Shape"])
+	expected = `export type Shape = Circle | Square`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	op = uc.GetStructAsInterfaceString(uc.Structs["github.com/N4r35h/gos2tsi/examplestructs.Square"])
+	expected = `export interface Square {
+kind: string
+side: number
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	// the members without a variant value don't narrow the union
+	op = ""
+	for _, d := range uc.Diagnostics {
+		if strings.HasPrefix(d.Message, "member of the union") {
+			op += d.Struct + "." + d.Field + ": " + d.Message + "\n"
+		}
+	}
+	expected = `Circle.Kind: member of the union Shape without a //gos2tsi:variant value for its discriminator
+Square.Kind: member of the union Shape without a //gos2tsi:variant value for its discriminator
+UserCreated.Type: member of the union Event without a //gos2tsi:variant value for its discriminator
+`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	uc.Strict = true
+	if err := uc.Err(); err == nil {
+		t.Errorf("strict mode must fail for the members without a variant value")
+	}
+}
+
+func TestInterfaceUnionParseOrder(t *testing.T) {
+	for _, order := range [][]any{
+		{exunionpkg.Envelope{}, exunionpkg2.Remote{}},
+		{exunionpkg2.Remote{}, exunionpkg.Envelope{}},
+	} {
+		oc := New()
+		for _, v := range order {
+			oc.ParseStruct(v)
+		}
+		op := oc.GetUnionAsTypeString(oc.Unions["github.com/N4r35h/gos2tsi/exunionpkg.Notice"])
+		expected := `export type Notice = Local | Remote`
+		if op != expected {
+			t.Errorf(expected)
+			t.Errorf(op)
+		}
+		op = oc.GetStructAsInterfaceString(oc.Structs["github.com/N4r35h/gos2tsi/exunionpkg2.Remote"])
+		expected = `export interface Remote {
+kind: "remote"
+url: string
+}`
+		if op != expected {
+			t.Errorf(expected)
+			t.Errorf(op)
+		}
+		schema, _ := json.Marshal(oc.GetOpenAPIComponents().Schemas["Notice"])
+		expected = `{"oneOf":[{"$ref":"#/components/schemas/Local"},{"$ref":"#/components/schemas/Remote"}],"discriminator":{"propertyName":"kind","mapping":{"local":"#/components/schemas/Local","remote":"#/components/schemas/Remote"}}}`
+		if string(schema) != expected {
+			t.Errorf(expected)
+			t.Errorf(string(schema))
+		}
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	dc := New()
+	ps := dc.ParseStruct(examplestructs.StructWithUnsupportedTypes{})
+	// the keys encoding/json rejects are typed as strings along with their diagnostics
+	for _, expected := range []string{"ratios: {[key: string]: string}\n", "flags: {[key: string]: number}\n"} {
+		if op := dc.GetStructAsInterfaceString(ps); !strings.Contains(op, expected) {
+			t.Errorf("missing %s in %s", expected, op)
+		}
+	}
+	expected := map[string]string{
+		"Updates":  "unsupported type chan int",
+		"Callback": "unsupported type func()",
+		"Complex":  "unsupported type complex128",
+		"Raw":      "unsupported type unsafe.Pointer",
+		"Handler":  "unsupported interface type examplestructs.unexportedInterface",
+		"Ratios":   "unsupported map key type float64",
+		"Flags":    "unsupported map key type bool",
+		"Stringer": "unsupported interface type fmt.Stringer",
+	}
+	found := 0
+	for _, d := range dc.Diagnostics {
+		if d.Struct != "StructWithUnsupportedTypes" {
+			continue
+		}
+		found++
+		if expected[d.Field] != d.Message {
+			t.Errorf("unexpected diagnostic %s", d)
+		}
+		if !strings.HasSuffix(d.Pos.Filename, "examplestructs.go") || d.Pos.Line == 0 {
+			t.Errorf("diagnostic must have the position of the field, got %s", d.Pos)
+		}
+	}
+	if found != len(expected) {
+		t.Errorf("expected %d diagnostics, got %d", len(expected), found)
+	}
+	if dc.Err() != nil {
+		t.Errorf("diagnostics must not be errors unless strict")
+	}
+	dc.Strict = true
+	if err := dc.Err(); err == nil || !strings.Contains(err.Error(), "StructWithUnsupportedTypes.Updates: unsupported type chan int") {
+		t.Errorf("strict mode must report the diagnostics as errors, got %v", err)
+	}
+
+	sc := New()
+	sc.Strict = true
+	sc.ParseStruct(examplestructs.SimpleStruct{})
+	if err := sc.Err(); err != nil {
+		t.Errorf("diagnostics of structs that aren't required must not be errors, got %v", err)
+	}
+}
+
+func TestUnexportedFields(t *testing.T) {
+	c := New()
+	ps := c.ParseStruct(examplestructs.StructWithUnexportedFields{})
+	op := c.GetStructAsInterfaceString(ps)
+	expected := `export interface StructWithUnexportedFields {
+created_by: string
+name: string
+"-": string
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+
+	ic := New()
+	ic.IncludeUnexported = true
+	ps = ic.ParseStruct(examplestructs.StructWithUnexportedFields{})
+	op = ic.GetStructAsInterfaceString(ps)
+	expected = `export interface StructWithUnexportedFields {
+created_by: string
+name: string
+"-": string
+secret: string
+count: number
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+}
+
+func TestEmbeddedFields(t *testing.T) {
+	ec := New()
+	ps := ec.ParseStruct(examplestructs.StructWithEmbeddedPointer{})
+	op := ec.GetStructAsInterfaceString(ps)
+	expected := `export interface StructWithEmbeddedPointer {
+id: number
+level: string
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+
+	// embedded types other than structs aren't flattened
+	uc := New()
+	uc.IncludeUnexported = true
+	ps = uc.ParseStruct(examplestructs.StructWithEmbeddedError{})
+	op = uc.GetStructAsInterfaceString(ps)
+	expected = `export interface StructWithEmbeddedError {
+error: unknown
+level: string
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+}
+
+func TestTSTag(t *testing.T) {
+	c := New()
+	ps := c.ParseStruct(examplestructs.StructWithTSTags{})
+	op := c.GetStructAsInterfaceString(ps)
+	expected := `export interface StructWithTSTags {
+userId: number
+readonly created_at: string
+nickname: string
+email?: string | null
+tags: string[] | null
+readonly Meta: Record<string, string>
+role: 'admin' | 'member'
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+
+	nc := New()
+	nc.NullablePointers = true
+	ps = nc.ParseStruct(examplestructs.StructWithTSTags{})
+	if email := nc.getFieldSignature(ps.Fields[3]); email != "email?: string | null" {
+		t.Errorf("nullable pointers must not be marked nullable twice, got %s", email)
+	}
+}
+
+func TestTagKeys(t *testing.T) {
+	c := New()
+	c.TagKeys = []string{"form", "query"}
+	ps := c.ParseStruct(examplestructs.ListOrdersQuery{})
+	op := c.GetStructAsInterfaceString(ps)
+	expected := `export interface ListOrdersQuery {
+page: number
+per_page?: number
+status?: string
+Sort: string
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+
+	mc := New()
+	mc.TagKeys = []string{"msgpack", "json"}
+	ps = mc.ParseStruct(examplestructs.ListOrdersQuery{})
+	op = mc.GetStructAsInterfaceString(ps)
+	expected = `export interface ListOrdersQuery {
+page_number: number
+PerPage: number
+st: string
+internal: string
+Sort: string
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+}
+
+func TestFieldNaming(t *testing.T) {
+	c := New()
+	ps := c.ParseStruct(examplestructs.StructWithFieldNames{})
+	op := c.GetStructAsInterfaceString(ps)
+	expected := `export interface StructWithFieldNames {
+UserID: number
+HTTPServer: string
+FieldWOJSONTag: string
+Tagged_As_Is: string
+"content-type": string
+"display name": string
+naïve: string
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+
+	cc := New()
+	cc.FieldNaming = CamelCase
+	ps = cc.ParseStruct(examplestructs.StructWithFieldNames{})
+	op = cc.GetStructAsInterfaceString(ps)
+	expected = `export interface StructWithFieldNames {
+userID: number
+httpServer: string
+fieldWOJSONTag: string
+Tagged_As_Is: string
+"content-type": string
+"display name": string
+naïve: string
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+
+	sc := New()
+	sc.FieldNaming = SnakeCase
+	ps = sc.ParseStruct(examplestructs.StructWithFieldNames{})
+	op = sc.GetStructAsInterfaceString(ps)
+	expected = `export interface StructWithFieldNames {
+user_id: number
+http_server: string
+field_wojson_tag: string
+Tagged_As_Is: string
+"content-type": string
+"display name": string
+naïve: string
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+
+	fc := New()
+	fc.FieldNaming = strings.ToUpper
+	ps = fc.ParseStruct(examplestructs.StructWithFieldNames{})
+	if ps.Fields[0].TSName != "USERID" || ps.Fields[3].TSName != "Tagged_As_Is" {
+		t.Errorf("custom naming must only apply to untagged fields, got %s and %s", ps.Fields[0].TSName, ps.Fields[3].TSName)
+	}
+}
+
+func TestOutputStyle(t *testing.T) {
+	sc := New()
+	sc.Indent = "  "
+	sc.Style = OutputStyle{
+		Terminator:      ";",
+		SingleQuotes:    true,
+		TrailingNewline: true,
+		BracketSpacing:  true,
+	}
+	ps := sc.ParseStruct(examplestructs.Profile{})
+	op := sc.GetStructAsInterfaceString(ps)
+	expected := `export interface Profile {
+  name: string;
+  tags: string[];
+  location: [number, number];
+  settings?: { [key: string]: string };
+  'content-type': string;
+}
+`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	sc.AddRoute(Route{Name: "getUser", Path: "/users/{id}/{tab}", Response: examplestructs.User{}})
+	op = sc.GetClientString()
+	for _, expected := range []string{"getUser: (params: { id: string | number; tab: string | number }): Promise<User> =>", "headers: body === undefined ? undefined : { 'Content-Type': 'application/json' },"} {
+		if !strings.Contains(op, expected) {
+			t.Errorf("expected %q in %s", expected, op)
+		}
+	}
+
+	tc := New()
+	tc.Style = OutputStyle{
+		TypeDeclarations: true,
+		Terminator:       ",",
+		Readonly:         true,
+		ReadonlyArrays:   true,
+		NoExport:         true,
+		Declare:          true,
+	}
+	ps = tc.ParseStruct(examplestructs.Profile{})
+	op = tc.GetStructAsInterfaceString(ps)
+	expected = `declare type Profile = {
+readonly name: string,
+readonly tags: ReadonlyArray<string>,
+readonly location: readonly [number, number],
+readonly settings?: {[key: string]: string},
+readonly "content-type": string,
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+
+	// declarations with bodies can't be ambient
+	dc := New()
+	dc.Style = OutputStyle{Declare: true}
+	ps = dc.ParseStruct(examplestructs.Profile{})
+	if op = dc.GetTypeGuardString(ps); !strings.HasPrefix(op, "export function isProfile(") {
+		t.Errorf(op)
+	}
+	if op = dc.GetClassString(ps); !strings.HasPrefix(op, "export class Profile {") {
+		t.Errorf(op)
+	}
+	if op = dc.GetFactoryString(ps); !strings.HasPrefix(op, "export function newProfile(") {
+		t.Errorf(op)
+	}
+
+	// literals are quoted the way the style in effect when emitting asks for
+	qc := New()
+	qc.ParseStruct(examplestructs.WebSocketMessage{})
+	qc.Style.SingleQuotes = true
+	if op = qc.GetEnumAsTypeString(qc.Enums["github.com/N4r35h/gos2tsi/examplestructs.OrderStatus"]); op != "export type OrderStatus = 'pending' | 'paid'" {
+		t.Errorf(op)
+	}
+	orderPaid := qc.Structs["github.com/N4r35h/gos2tsi/examplestructs.OrderPaid"]
+	for op, expected := range map[string]string{
+		qc.GetStructAsInterfaceString(orderPaid): "\ntype: 'order_paid'\n",
+		qc.GetTypeGuardString(orderPaid):         "o.type === 'order_paid'",
+		qc.GetClassString(orderPaid):             "\ntype: 'order_paid' = 'order_paid'\n",
+		qc.GetFactoryString(orderPaid):           "\ntype: 'order_paid',\n",
+	} {
+		if !strings.Contains(op, expected) {
+			t.Errorf("expected %q in %s", expected, op)
+		}
+	}
+}
+
+// newLargeParsedStruct builds a struct with fieldCount fields without loading any package
+func newLargeParsedStruct(fieldCount int) ParsedStruct {
+	ps := ParsedStruct{Name: "LargeStruct", Required: true}
+	for i := 0; i < fieldCount; i++ {
+		name := "Field" + strconv.Itoa(i)
+		ps.Fields = append(ps.Fields, ParsedField{
+			Var:     types.NewField(token.NoPos, nil, name, types.Typ[types.String], false),
+			Tag:     `json:"` + name + `,omitempty"`,
+			TSName:  name,
+			TSType:  "string",
+			IsSlice: i % 3,
+		})
+	}
+	return ps
+}
+
+// concatStructAsInterfaceString is GetStructAsInterfaceString the way it was before the emitter,
+// concatenating strings, it's the baseline of the benchmarks below
+func concatStructAsInterfaceString(c *Converter, ps ParsedStruct) string {
+	toRet := ""
+	if doc := c.Docs[removeGenericsPartFromStructName(ps.Name)]; doc != "" {
+		toRet += c.GetFormattedTSComment(doc) + "\n"
+	}
+	if ps.Name == "" {
+		return ""
+	}
+	if c.Style.TypeDeclarations {
+		toRet += c.getDeclarationPrefix("type") + c.getInterfaceName(ps) + " = {"
+	} else {
+		toRet += c.getDeclarationPrefix("interface") + c.getInterfaceName(ps) + " {"
+	}
+	for _, pf := range c.flattenEmbeddedFields(ps.Fields) {
+		signature := c.getPropertyName(pf.TSName)
+		if pf.Readonly || c.Style.Readonly {
+			signature = "readonly " + signature
+		}
+		if c.isOptionalField(pf) {
+			signature += "?"
+		}
+		TSType := c.postProcessTSTypeName(pf.TSType)
+		for i := 0; i < pf.IsSlice; i++ {
+			TSType = c.sliceOf(TSType)
+		}
+		if pf.Nullable && !strings.HasSuffix(TSType, " | null") {
+			TSType += " | null"
+		}
+		toRet += "\n" + c.Indent + signature + ": " + TSType + c.Style.Terminator
+	}
+	toRet += "\n}"
+	return c.endDeclaration(toRet)
+}
+
+func TestConcatStructAsInterfaceString(t *testing.T) {
+	bc := New()
+	ps := newLargeParsedStruct(20)
+	if op, expected := bc.GetStructAsInterfaceString(ps), concatStructAsInterfaceString(bc, ps); op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+}
+
+func BenchmarkConcatStructAsInterfaceString(b *testing.B) {
+	bc := New()
+	ps := newLargeParsedStruct(500)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = concatStructAsInterfaceString(bc, ps)
+	}
+}
+
+func BenchmarkStructAsInterfaceString(b *testing.B) {
+	bc := New()
+	ps := newLargeParsedStruct(500)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = bc.GetStructAsInterfaceString(ps)
+	}
+}
+
+func BenchmarkWriteStructAsInterface(b *testing.B) {
+	bc := New()
+	ps := newLargeParsedStruct(500)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		bc.WriteStructAsInterface(io.Discard, ps)
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	wc := New()
+	wc.ParseStruct(examplestructs.EventEnvelope{})
+	wc.ParseStruct(examplestructs.Page[examplestructs.User]{})
+	var sb strings.Builder
+	n, err := wc.WriteTo(&sb)
+	if err != nil || n != int64(sb.Len()) {
+		t.Errorf("WriteTo must report the bytes written, got %d of %d, %v", n, sb.Len(), err)
+	}
+	op := sb.String()
+	for _, declaration := range []string{
+		wc.GetStructAsInterfaceString(wc.Structs["github.com/N4r35h/gos2tsi/examplestructs.EventEnvelope"]),
+		wc.GetStructAsInterfaceString(wc.Structs["github.com/N4r35h/gos2tsi/examplestructs.UserCreated"]),
+		wc.GetUnionAsTypeString(wc.Unions["github.com/N4r35h/gos2tsi/examplestructs.Event"]),
+		"export type Page_User = Page<User>",
+	} {
+		if !strings.Contains(op, declaration+"\n") {
+			t.Errorf("missing declaration %s in %s", declaration, op)
+		}
+	}
+
+	ps := wc.Structs["github.com/N4r35h/gos2tsi/examplestructs.EventEnvelope"]
+	sb.Reset()
+	if _, err := wc.WriteStructAsInterface(&sb, ps); err != nil || sb.String() != wc.GetStructAsInterfaceString(ps) {
+		t.Errorf("WriteStructAsInterface must write what GetStructAsInterfaceString returns, got %s", sb.String())
+	}
+}
+
+func TestWriteToOnlyReachable(t *testing.T) {
+	rc := New()
+	rc.ParseStruct(examplestructs.SimpleStruct{})
+	var sb strings.Builder
+	rc.WriteTo(&sb)
+	rc.WriteTypeGuards(&sb)
+	rc.WriteFactories(&sb)
+	op := sb.String()
+	expected := `export interface SimpleStruct {
+test: string
+}
+export function isSimpleStruct(v: unknown): v is SimpleStruct {
+if (typeof v !== "object" || v === null || Array.isArray(v)) return false
+const o = v as Record<string, unknown>
+return typeof o.test === "string"
+}
+export function newSimpleStruct(): SimpleStruct {
+return {
+test: "",
+}
+}
+`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+
+	// the roots added later are marked along with the earlier ones, not the instantiations parsed with them
+	rc.ParseStruct(examplestructs.Page[examplestructs.User]{})
+	if !rc.Structs["github.com/N4r35h/gos2tsi/examplestructs.SimpleStruct"].Required || !rc.Structs["github.com/N4r35h/gos2tsi/examplestructs.User"].Required {
+		t.Errorf("the structs reachable from the roots must be required")
+	}
+	for name, ps := range rc.Instantiations {
+		if ps.Required != (name == "Page_User") {
+			t.Errorf("only the instantiations the roots refer to must be required, got %s %v", name, ps.Required)
+		}
+	}
+}
+
+func TestTypeGuards(t *testing.T) {
+	gc := New()
+	ps := gc.ParseStruct(examplestructs.WebSocketMessage{})
+	op := gc.GetTypeGuardString(ps)
+	expected := `export function isWebSocketMessage(v: unknown): v is WebSocketMessage {
+if (typeof v !== "object" || v === null || Array.isArray(v)) return false
+const o = v as Record<string, unknown>
+return typeof o.id === "number" &&
+typeof o.active === "boolean" &&
+Array.isArray(o.grid) && o.grid.every((e0: unknown) => Array.isArray(e0) && e0.every((e1: unknown) => typeof e1 === "number")) &&
+Array.isArray(o.position) && o.position.length === 2 && o.position.every((e0: unknown) => typeof e0 === "number") &&
+(o.scores === undefined || typeof o.scores === "object" && o.scores !== null && !Array.isArray(o.scores) && Object.values(o.scores as object).every((v0: unknown) => typeof v0 === "number")) &&
+isUser(o.sender) &&
+(o.reply_to === null || isUser(o.reply_to)) &&
+isPage(o.page, isUser) &&
+Array.isArray(o.Pairs) && o.Pairs.every((e0: unknown) => isPair(e0, (x1: unknown): x1 is string => typeof x1 === "string", isOrder)) &&
+typeof o.status === "string" &&
+typeof o.sent_at === "string" &&
+isEvent(o.event)
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+
+	op = gc.GetTypeGuardString(gc.Structs["github.com/N4r35h/gos2tsi/examplestructs.Page"])
+	expected = `export function isPage<T>(v: unknown, isT: (v: unknown) => v is T): v is Page<T> {
+if (typeof v !== "object" || v === null || Array.isArray(v)) return false
+const o = v as Record<string, unknown>
+return Array.isArray(o.items) && o.items.every((e0: unknown) => isT(e0)) &&
+typeof o.total === "number"
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+
+	op = gc.GetTypeGuardString(gc.Structs["github.com/N4r35h/gos2tsi/examplestructs.OrderPaid"])
+	if !strings.Contains(op, `o.type === "order_paid"`) {
+		t.Errorf("discriminator literals must be compared, got %s", op)
+	}
+	// only the discriminators are compared, not the overrides with literal types
+	op = gc.GetTypeGuardString(gc.ParseStruct(examplestructs.StructWithTSTags{}))
+	if strings.Contains(op, "o.role") {
+		t.Errorf("the literal types set via the ts tag aren't discriminators, got %s", op)
+	}
+
+	op = gc.GetUnionTypeGuardString(gc.Unions["github.com/N4r35h/gos2tsi/examplestructs.Event"])
+	expected = `export function isEvent(v: unknown): v is Event {
+return isUserCreated(v) || isOrderPaid(v)
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+}
+
+func TestClasses(t *testing.T) {
+	cc := New()
+	ps := cc.ParseStruct(examplestructs.WebSocketMessage{})
+	op := cc.GetClassString(ps)
+	expected := `export class WebSocketMessage {
+id = 0
+active = false
+grid: number[][] = []
+position: [number, number] = [0, 0]
+scores?: {[key: string]: number} = {}
+sender: User = new User()
+reply_to: User | null = null
+page: Page<User> = new Page<User>()
+Pairs: Pair<string, Order>[] = []
+status: OrderStatus = "" as unknown as OrderStatus
+payload: any = null
+sent_at = ""
+event: Event | null = null
+
+constructor(init?: Partial<WebSocketMessage>) {
+Object.assign(this, init)
+}
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+
+	gc := New()
+	gc.Indent = "  "
+	gc.Style.Terminator = ";"
+	ps = gc.ParseStruct(examplestructs.Tree[int]{})
+	op = gc.GetClassString(ps)
+	expected = `export class Tree<T> {
+  value!: T;
+  children: Tree<T>[] = [];
+
+  constructor(init?: Partial<Tree<T>>) {
+    Object.assign(this, init);
+  }
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+
+	// the overrides with literal types aren't discriminators, they have no zero value
+	op = cc.GetClassString(cc.ParseStruct(examplestructs.StructWithTSTags{}))
+	if !strings.Contains(op, "\nrole!: 'admin' | 'member'\n") {
+		t.Errorf(op)
+	}
+	op = cc.GetClassString(cc.Structs["github.com/N4r35h/gos2tsi/examplestructs.OrderPaid"])
+	if !strings.Contains(op, `type: "order_paid" = "order_paid"`) {
+		t.Errorf("discriminators must be initialized to their literal, got %s", op)
+	}
+}
+
+func TestFactories(t *testing.T) {
+	fc := New()
+	ps := fc.ParseStruct(examplestructs.CreateOrderRequest{})
+	op := fc.GetFactoryString(ps)
+	expected := `export function newCreateOrderRequest(): CreateOrderRequest {
+return {
+customer_id: 0,
+items: [],
+metadata: {},
+coupon: null as unknown as string,
+shipping: newUser(),
+location: [0, 0],
+page: newPage(newUser),
+}
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	// the zero values of the go types are cast to the literal types set via the ts tag
+	op = fc.GetFactoryString(fc.ParseStruct(examplestructs.StructWithTSTags{}))
+	if !strings.Contains(op, "\nrole: \"\" as unknown as 'admin' | 'member',\n") {
+		t.Errorf(op)
+	}
+	// omitempty fields made required via the ts tag are kept
+	if !strings.Contains(op, "\nnickname: \"\",\n") {
+		t.Errorf(op)
+	}
+	op = fc.GetFactoryString(fc.ParseStruct(examplestructs.OrderPaid{}))
+	if !strings.Contains(op, `type: "order_paid",`) {
+		t.Errorf("discriminators must be set to their literal, got %s", op)
+	}
+	// the omitzero and omitempty fields the factory leaves out are optional in the interface
+	op = fc.GetStructAsInterfaceString(ps)
+	expected = `export interface CreateOrderRequest {
+customer_id: number
+note?: string
+items: Order[]
+metadata: {[key: string]: string}
+coupon: string
+shipping?: User
+location: [number, number]
+urgent?: boolean
+page: Page<User>
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+
+	nc := New()
+	nc.FactoryNilSlices = NilSlicesAsNull
+	nc.NullablePointers = true
+	nc.Indent = "  "
+	ps = nc.ParseStruct(examplestructs.CreateOrderRequest{})
+	op = nc.GetFactoryString(ps)
+	expected = `export function newCreateOrderRequest(): CreateOrderRequest {
+  return {
+    customer_id: 0,
+    items: null as unknown as Order[],
+    metadata: null as unknown as {[key: string]: string},
+    coupon: null,
+    shipping: newUser(),
+    location: [0, 0],
+    page: newPage(newUser),
+  }
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+
+	op = nc.GetFactoryString(nc.Structs["github.com/N4r35h/gos2tsi/examplestructs.Page"])
+	expected = `export function newPage<T>(newT: () => T): Page<T> {
+  return {
+    items: null as unknown as T[],
+    total: 0,
+  }
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+}
+
+func TestMarshalers(t *testing.T) {
+	mc := New()
+	mc.ParseStruct(examplestructs.StructWithMarshalers{})
+	var sb strings.Builder
+	mc.WriteTo(&sb)
+	op := sb.String()
+	// the marshalers with pointer receivers are used for the fields of addressable structs
+	expected := `export interface StructWithMarshalers {
+price: unknown
+discount: unknown
+sku: string
+related: string[]
+prices: {[key: string]: unknown}
+raw: unknown
+at: string
+}
+`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+}
+
+func TestEncodedTypes(t *testing.T) {
+	ec := New()
+	ps := ec.ParseStruct(examplestructs.StructWithEncodedTypes{})
+	var sb strings.Builder
+	ec.WriteTo(&sb)
+	ec.WriteTypeGuards(&sb)
+	ec.WriteFactories(&sb)
+	ec.WriteClass(&sb, ps)
+	op := sb.String()
+	expected := `export interface StructWithEncodedTypes {
+at: string
+times: string[]
+data: string
+where: string
+raw: unknown
+chunks: string[]
+}
+export function isStructWithEncodedTypes(v: unknown): v is StructWithEncodedTypes {
+if (typeof v !== "object" || v === null || Array.isArray(v)) return false
+const o = v as Record<string, unknown>
+return typeof o.at === "string" &&
+Array.isArray(o.times) && o.times.every((e0: unknown) => typeof e0 === "string") &&
+typeof o.data === "string" &&
+typeof o.where === "string" &&
+Array.isArray(o.chunks) && o.chunks.every((e0: unknown) => typeof e0 === "string")
+}
+export function newStructWithEncodedTypes(): StructWithEncodedTypes {
+return {
+at: "0001-01-01T00:00:00Z",
+times: [],
+data: "",
+where: "",
+raw: null,
+chunks: [],
+}
+}
+export class StructWithEncodedTypes {
+at = "0001-01-01T00:00:00Z"
+times: string[] = []
+data = ""
+where = ""
+raw: unknown = null
+chunks: string[] = []
+
+constructor(init?: Partial<StructWithEncodedTypes>) {
+Object.assign(this, init)
+}
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+}
+
+func TestOpenAPIComponents(t *testing.T) {
+	oc := New()
+	oc.ParseStruct(examplestructs.CreateOrderRequest{})
+	oc.ParseStruct(examplestructs.EventEnvelope{})
+	oc.ParseStruct(examplestructs.StructWithGenericFields[string, int]{})
+	schemas := oc.GetOpenAPIComponents().Schemas
+	for name, expected := range map[string]string{
+		"CreateOrderRequest":                    `{"type":"object","properties":{"customer_id":{"type":"integer"},"note":{"type":"string"},"items":{"type":"array","items":{"$ref":"#/components/schemas/Order"}},"metadata":{"type":"object","additionalProperties":{"type":"string"}},"coupon":{"type":["string","null"]},"shipping":{"$ref":"#/components/schemas/User"},"location":{"type":"array","items":{"type":"number","format":"double"},"minItems":2,"maxItems":2},"urgent":{"type":"boolean"},"page":{"$ref":"#/components/schemas/Page_User"}},"required":["customer_id","items","metadata","coupon","location","page"]}`,
+		"Page_User":                             `{"type":"object","properties":{"items":{"type":"array","items":{"$ref":"#/components/schemas/User"}},"total":{"type":"integer"}},"required":["items","total"]}`,
+		"Event":                                 `{"description":"Event is implemented by every event payload","oneOf":[{"$ref":"#/components/schemas/UserCreated"},{"$ref":"#/components/schemas/OrderPaid"}]}`,
+		"OrderPaid":                             `{"type":"object","properties":{"type":{"type":"string","const":"order_paid"},"amount":{"type":"number","format":"double"}},"required":["type","amount"]}`,
+		"StructWithGenericFields_string_number": `{"type":"object","properties":{"items":{"$ref":"#/components/schemas/Page_User"},"cache":{"type":"object","additionalProperties":{"$ref":"#/components/schemas/Result_Order_unknown"}},"nested":{"$ref":"#/components/schemas/Wrapper_Pair_string_number_Array"},"pages":{"type":"array","items":{"$ref":"#/components/schemas/Page_Pair_string_User"}},"tree":{"$ref":"#/components/schemas/Tree_number"}},"required":["items","cache","nested","pages","tree"]}`,
+		"Wrapper_Pair_string_number_Array":      `{"type":"object","properties":{"data":{"type":"array","items":{"$ref":"#/components/schemas/Pair_string_number"}}},"required":["data"]}`,
+		"Tree_number":                           `{"type":"object","properties":{"value":{"type":"integer"},"children":{"type":"array","items":{"$ref":"#/components/schemas/Tree_number"}}},"required":["value","children"]}`,
+	} {
+		op, err := json.Marshal(schemas[name])
+		if err != nil || string(op) != expected {
+			t.Errorf(expected)
+			t.Errorf(string(op))
+		}
+	}
+	if _, exists := schemas["Page"]; exists {
+		t.Errorf("generic structs must only get schemas for their instantiations")
+	}
+	// the default mappings are the ones the converter was created with
+	GoTypeToTSType["int"] = "string"
+	op, _ := json.Marshal(oc.GetOpenAPIComponents().Schemas["Page_User"])
+	GoTypeToTSType["int"] = "number"
+	if !strings.Contains(string(op), `"total":{"type":"integer"}`) {
+		t.Errorf("changing GoTypeToTSType must not affect the existing converters, got %s", op)
+	}
+
+	var sb strings.Builder
+	if err := oc.WriteOpenAPIYAML(&sb, OpenAPIInfo{Title: "API", Version: "1.0.0"}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	for _, expected := range []string{"openapi: 3.1.0\ninfo:\n  title: API\n  version: 1.0.0\ncomponents:\n  schemas:\n", `
+    Order:
+      type: object
+      properties:
+        id:
+          type: integer
+          minimum: 0
+      required:
+        - id
+`} {
+		if !strings.Contains(sb.String(), expected) {
+			t.Errorf("missing %s in %s", expected, sb.String())
+		}
+	}
+
+	for goType, expected := range map[string]string{
+		"int16":  `{"type":"integer","format":"int32"}`,
+		"int64":  `{"type":"integer","format":"int64"}`,
+		"uint16": `{"type":"integer","format":"int32","minimum":0}`,
+		"uint32": `{"type":"integer","minimum":0}`,
+		"uint64": `{"type":"integer","minimum":0}`,
+	} {
+		op, err := json.Marshal(oc.getTypeSchema(types.Universe.Lookup(goType).Type(), schemas))
+		if err != nil || string(op) != expected {
+			t.Errorf(expected)
+			t.Errorf(string(op))
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	rc := New()
+	rc.AddRoute(Route{Name: "getUser", Path: "/users/{id}", Response: examplestructs.User{}})
+	rc.AddRoute(Route{Name: "listOrders", Path: "/orders", Query: examplestructs.ListOrdersQuery{}, Response: examplestructs.Page[examplestructs.Order]{}})
+	rc.AddRoute(Route{Name: "createOrder", Method: "post", Path: "/users/:userID/orders", Request: &examplestructs.CreateOrderRequest{}, Response: []examplestructs.Order{}})
+	rc.AddRoute(Route{Name: "deleteOrder", Method: "DELETE", Path: "/orders/{id}"})
+	rc.AddRoute(Route{Name: "cancelOrder", Method: "POST", Path: "/orders/:id/items:cancel", Response: examplestructs.Order{}})
+	op := rc.GetClientString()
+	expected := `export function createClient(baseURL = "", fetcher: Fetcher = (input, init) => fetch(input, init)) {
+return {
+getUser: (params: {id: string | number}): Promise<User> =>
+request<User>(fetcher, "GET", baseURL + ` + "`/users/${encodeURIComponent(String(params.id))}`" + `),
+listOrders: (query: ListOrdersQuery): Promise<Page<Order>> =>
+request<Page<Order>>(fetcher, "GET", baseURL + "/orders", query),
+createOrder: (params: {userID: string | number}, body: CreateOrderRequest): Promise<Order[]> =>
+request<Order[]>(fetcher, "POST", baseURL + ` + "`/users/${encodeURIComponent(String(params.userID))}/orders`" + `, undefined, body),
+deleteOrder: (params: {id: string | number}): Promise<void> =>
+request<void>(fetcher, "DELETE", baseURL + ` + "`/orders/${encodeURIComponent(String(params.id))}`" + `, undefined, undefined, false),
+cancelOrder: (params: {id: string | number}): Promise<Order> =>
+request<Order>(fetcher, "POST", baseURL + ` + "`/orders/${encodeURIComponent(String(params.id))}/items:cancel`" + `),
+}
+}
+
+export const api = createClient()`
+	if !strings.HasSuffix(op, expected) {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	// empty bodies, and the bodies of routes without a response, aren't parsed
+	if !strings.Contains(op, `if (!parse || text === "") return undefined as T`) {
+		t.Errorf("the client must not parse empty bodies, got %s", op)
+	}
+	dc := New()
+	dc.Style.Declare = true
+	dc.AddRoute(Route{Name: "getUser", Path: "/users/{id}", Response: examplestructs.User{}})
+	op = dc.GetClientString()
+	for _, declaration := range []string{"export declare type Fetcher = ", "\nexport function createClient(", "\nexport const api = createClient()"} {
+		if !strings.Contains(op, declaration) {
+			t.Errorf("expected %q in %s", declaration, op)
+		}
+	}
+
+	var sb strings.Builder
+	if _, err := rc.WriteClient(&sb); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	for _, name := range []string{"User", "Order", "ListOrdersQuery", "CreateOrderRequest"} {
+		if !strings.Contains(sb.String(), "export interface "+name+" {") {
+			t.Errorf("missing interface %s in %s", name, sb.String())
+		}
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	hc := New()
+	handlers := hc.ParseHandlers("github.com/N4r35h/gos2tsi/exhandlerpkg")
+	op := ""
+	for _, handler := range handlers {
+		op += handler.Name + "(" + handler.RequestTSType + "): " + handler.ResponseTSType + "\n"
+	}
+	expected := `CreateOrder(CreateOrderRequest): ErrorResponse | Order
+Server.ListOrders(): Page<Order>
+GetUser.func1(): User
+Health(): 
+ImportUser(User): 
+`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	if ps := hc.Structs["github.com/N4r35h/gos2tsi/exhandlerpkg.ErrorResponse"]; !ps.Required {
+		t.Errorf("the types of handlers must be parsed as required structs")
+	}
+	for _, name := range []string{"Profile", "Point"} {
+		if ps := hc.Structs["github.com/N4r35h/gos2tsi/examplestructs."+name]; ps.Required {
+			t.Errorf("only the values decoded from the request body and encoded to the response must be parsed as required structs, got %s", name)
+		}
+	}
+}
+
+func TestExportDirectives(t *testing.T) {
+	ec := New()
+	exported, err := ec.ParseExportedTypes("./examplestructs/...")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(exported) != 2 || exported[0].Name != "Account" || exported[1].Name != "AccountSettings" {
+		t.Errorf("expected Account and AccountSettings to be exported, got %v", exported)
+	}
+	op := ec.GetStructAsInterfaceString(ec.Structs["github.com/N4r35h/gos2tsi/examplestructs.AccountSettings"])
+	expected := `export interface AccountSettings {
+theme: string
+linked: ApiAccount[]
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	op = ec.GetStructAsInterfaceString(exported[0])
+	expected = `
+/**
+Account is picked up by ParseExportedTypes
+*/
+export interface ApiAccount {
+id: number
+owner: User
+settings: AccountSettings
+}`
+	if op != expected {
+		t.Errorf(expected)
+		t.Errorf(op)
+	}
+	if op = ec.GetFactoryString(exported[0]); !strings.HasPrefix(op, "export function newApiAccount(): ApiAccount {") {
+		t.Errorf(op)
+	}
+	for _, pattern := range []string{"./nope/...", "./nope"} {
+		if _, err := New().ParseExportedTypes(pattern); err == nil {
+			t.Errorf("expected an error for %s", pattern)
+		}
+	}
+}
+
+func TestRunConfig(t *testing.T) {
+	moduleDir, _ := os.Getwd()
+	configDir := t.TempDir()
+	configPath := filepath.Join(configDir, "gos2tsi.yaml")
+	config := `dir: ` + strconv.Quote(moduleDir) + `
+targets:
+  - name: public
+    output: public/api.ts
+    packages: [./examplestructs/...]
+    style:
+      terminator: ";"
+  - name: internal
+    output: internal.ts
+    types: ["github.com/N4r35h/gos2tsi/examplestructs.Page[github.com/N4r35h/gos2tsi/examplestructs.Order]"]
+    type_guards: true
+  - name: orders
+    output: orders.ts
+    types: [github.com/N4r35h/gos2tsi/examplestructs.Order]
+`
+	if err := os.WriteFile(configPath, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunConfig(configPath); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	public, _ := os.ReadFile(filepath.Join(configDir, "public", "api.ts"))
+	expected := `export interface AccountSettings {
+theme: string;
+linked: ApiAccount[];
+}`
+	if !strings.Contains(string(public), expected) {
+		t.Errorf(expected)
+		t.Errorf(string(public))
+	}
+	internal, _ := os.ReadFile(filepath.Join(configDir, "internal.ts"))
+	for _, expected := range []string{"export type Page_Order = Page<Order>", "export function isOrder(v: unknown): v is Order {"} {
+		if !strings.Contains(string(internal), expected) {
+			t.Errorf("missing %s in %s", expected, internal)
+		}
+	}
+	// only the root and what it refers to is output, not the rest of its package
+	orders, _ := os.ReadFile(filepath.Join(configDir, "orders.ts"))
+	expected = `export interface Order {
+id: number
+}
+`
+	if string(orders) != expected {
+		t.Errorf(expected)
+		t.Errorf(string(orders))
+	}
+
+	if err := RunConfig(configPath, "admin"); err == nil {
+		t.Errorf("expected an error for an unknown target")
+	}
+	missingPath := filepath.Join(configDir, "missing.yaml")
+	os.WriteFile(missingPath, []byte(`dir: `+strconv.Quote(moduleDir)+`
+targets:
+  - name: missing
+    output: missing.ts
+    packages: [./nope/...]
+`), 0o644)
+	if err := RunConfig(missingPath); err == nil {
+		t.Errorf("expected an error for a pattern matching no packages")
+	}
+	if _, err := os.Stat(filepath.Join(configDir, "missing.ts")); err == nil {
+		t.Errorf("no output must be written for a target that failed")
+	}
+	jsonPath := filepath.Join(configDir, "gos2tsi.json")
+	os.WriteFile(jsonPath, []byte(`{"targets": [{"name": "public", "output": "api.ts", "field_namin": "camel"}]}`), 0o644)
+	if _, err := LoadConfig(jsonPath); err == nil {
+		t.Errorf("expected an error for an unknown key")
+	}
+}
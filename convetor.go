@@ -2,13 +2,17 @@ package gos2tsi
 
 import (
 	"go/doc"
+	"go/token"
 	"go/types"
 	"reflect"
 	"strings"
+	"unicode"
 
 	"golang.org/x/tools/go/packages"
 )
 
+// GoTypeToTSType holds the default mappings every Converter created with New starts with,
+// changing it after a Converter is created has no effect on that Converter
 var GoTypeToTSType = map[string]string{
 	"bool":        "boolean",
 	"interface{}": "any",
@@ -24,8 +28,12 @@ var GoTypeToTSType = map[string]string{
 	"uint16":      "number",
 	"uint32":      "number",
 	"uint64":      "number",
+	"byte":        "number",
+	"rune":        "number",
 	"float32":     "number",
 	"float64":     "number",
+	"uintptr":     "number",
+	"error":       "unknown",
 }
 
 type ParsedField struct {
@@ -35,6 +43,11 @@ type ParsedField struct {
 	TSType    string
 	IsSlice   int
 	RefStruct ParsedStruct
+	// Optional, Readonly and Nullable are set from the ts tag, Optional also from omitempty
+	// and optional:"true"
+	Optional bool
+	Readonly bool
+	Nullable bool
 }
 
 type ParsedStruct struct {
@@ -46,6 +59,26 @@ type ParsedStruct struct {
 	Fields             []ParsedField
 	IsSlice            int
 	GenericPopulations []ParsedField
+	// Recursive is set on references to a struct that were made while it was still being parsed,
+	// these only identify the struct and carry no Fields
+	Recursive bool
+}
+
+// ParsedAlias is a go type alias, only collected when EmitTypeAliases is set
+type ParsedAlias struct {
+	PackageName string
+	PackgePath  string
+	ID          string
+	Name        string
+	TypeParams  []string
+	TSType      string
+	// Required is set for the aliases the roots refer to, the ones that get output
+	Required bool
+}
+
+type structObject struct {
+	obj *types.TypeName
+	pkg *packages.Package
 }
 
 type Converter struct {
@@ -53,13 +86,103 @@ type Converter struct {
 	Structs              map[string]ParsedStruct
 	Docs                 map[string]string
 	AlreadyParsedPackage map[string]bool
+	// NullablePointers emits pointer types as T | null
+	NullablePointers bool
+	// Instantiations holds the generic structs parsed with their type arguments, keyed by the
+	// name GetInstantiationName gives them
+	Instantiations map[string]ParsedStruct
+	// InstantiationNamer names the type aliases emitted for generic instantiations, it's given the
+	// struct name and the TS types of its type arguments, defaults to joining them with "_"
+	InstantiationNamer func(name string, typeArgs []string) string
+	// EmitTypeAliases refers to go type aliases by their name and collects their declarations
+	// in TypeAliases, otherwise aliases are resolved to the aliased type
+	EmitTypeAliases bool
+	TypeAliases     map[string]ParsedAlias
+	// UnionInterfaces declares fully qualified interfaces to be emitted as a union of the structs
+	// implementing them in the loaded packages, same as the //gos2tsi:union directive, the value
+	// is the json name of the discriminator field or "" for none
+	UnionInterfaces map[string]string
+	Unions          map[string]ParsedUnion
+	// Enums holds the basic types with declared constants, emitted as the union of their values
+	Enums map[string]ParsedEnum
+	// Diagnostics lists the fields whose types couldn't be converted
+	Diagnostics []Diagnostic
+	// Strict makes Err report the Diagnostics
+	Strict bool
+	// TypeMappings maps fully qualified go types to the TS type emitted for them, see MapType
+	TypeMappings map[string]string
+	// TypeMapper if set is consulted before TypeMappings for every type that gets converted
+	TypeMapper TypeMapper
+	// MaxTupleLength is the longest go array that gets emitted as a TS tuple, longer ones are
+	// emitted as T[] instead, 0 emits every array as a tuple
+	MaxTupleLength int64
+	// MapsAsRecord emits maps as Record<K, V> instead of {[key: K]: V}
+	MapsAsRecord bool
+	// HoistInlineStructs emits anonymous struct fields as named interfaces (added to Structs)
+	// called <Struct>_<Field> instead of inline object literal types
+	HoistInlineStructs bool
+	// ByteArraysAsString emits [N]byte as string, encoding/json itself encodes these as arrays of
+	// numbers so this is meant for byte array types with a custom (base64, hex, ...) marshaler
+	ByteArraysAsString bool
+	// Style configures the keywords, punctuation and modifiers of the output
+	Style OutputStyle
+	// FactoryNilSlices is how the factories emit nil slices and maps, [] and {} by default
+	FactoryNilSlices NilSlicePolicy
+	// IncludeUnexported emits unexported fields as well, by default they are skipped like
+	// encoding/json does
+	IncludeUnexported bool
+	// TagKeys are the struct tags naming fields and marking them omitempty or skipped ("-"),
+	// the first one present on a field is used, defaults to json
+	TagKeys []string
+	// FieldNaming renames the fields without a name in their tags, ex: CamelCase or SnakeCase,
+	// nil keeps the go name like encoding/json does
+	FieldNaming func(name string) string
+	// Routes are the endpoints the client is generated for, see AddRoute
+	Routes []ParsedRoute
+	// Dir is the directory packages are loaded from, relative package patterns are resolved against
+	// it and it must be with in the module of the loaded packages, the current directory if empty
+	Dir string
+
+	fset          *token.FileSet
+	structObjects map[string]structObject
+	// defaultTypeMappings are the GoTypeToTSType mappings New started TypeMappings with
+	defaultTypeMappings map[string]string
+	// loadedPackages holds the packages loaded by parsePackage by their path
+	loadedPackages map[string]*packages.Package
+	// roots are the types parsed on request, see markRequired
+	roots []types.Type
+	// markedRoots and markedStructs are the number of roots and struct objects markRequired saw
+	// last, requiredVisited what it marked so far
+	markedRoots     int
+	markedStructs   int
+	requiredVisited map[string]bool
+	// instantiationTypes holds the reflect name of the type of each of the Instantiations
+	instantiationTypes map[string]string
+	parsingStructs     map[string]bool
+	parseStack         []string
+	directives         map[string][]string
+	discriminators     map[string]discriminatorField
 }
 
 func New() *Converter {
+	typeMappings := make(map[string]string, len(GoTypeToTSType))
+	defaultTypeMappings := make(map[string]string, len(GoTypeToTSType))
+	for goType, tsType := range GoTypeToTSType {
+		typeMappings[goType] = tsType
+		defaultTypeMappings[goType] = tsType
+	}
 	return &Converter{
 		Structs:              map[string]ParsedStruct{},
 		Docs:                 map[string]string{},
 		AlreadyParsedPackage: map[string]bool{},
+		Instantiations:       map[string]ParsedStruct{},
+		TypeAliases:          map[string]ParsedAlias{},
+		UnionInterfaces:      map[string]string{},
+		Unions:               map[string]ParsedUnion{},
+		Enums:                map[string]ParsedEnum{},
+		TypeMappings:         typeMappings,
+		defaultTypeMappings:  defaultTypeMappings,
+		TagKeys:              []string{"json"},
 	}
 }
 
@@ -76,182 +199,266 @@ func (c *Converter) ParseStruct(interf interface{}) ParsedStruct {
 }
 
 func (c *Converter) ensureGenericPopulations(structName string) {
-	arrayTrimmedStructName := strings.Trim(structName, "[]")
-	genericPopulations := strings.SplitN(arrayTrimmedStructName, "[", 2)
-	if len(genericPopulations) > 1 {
-		closingBracketTrimmed := strings.Trim(genericPopulations[1], "]")
-		for _, gp := range strings.Split(closingBracketTrimmed, ",") {
-			arrayTrimmed := strings.Trim(gp, "[]")
-			fullNameSegments := strings.Split(arrayTrimmed, ".")
-			if len(fullNameSegments) > 1 {
-				isSlice := 0
-				if strings.HasPrefix(gp, "[]") {
-					isSlice = 1
-				}
-				pkgPath := strings.Join(fullNameSegments[:len(fullNameSegments)-1], ".")
-				structName := fullNameSegments[len(fullNameSegments)-1]
-				c.ParseStructsInPackage(pkgPath, structName, isSlice)
-			}
+	_, typeArgs := splitTypeArgs(structName)
+	for _, typeArg := range typeArgs {
+		isSlice := countPrefixBrackets(typeArg)
+		typeArg = strings.TrimLeft(typeArg, "[]*")
+		if strings.HasPrefix(typeArg, "map[") {
+			continue
+		}
+		baseName, _ := splitTypeArgs(typeArg)
+		if dot := strings.LastIndex(baseName, "."); dot > 0 {
+			c.parseStructsInPackage(baseName[:dot], typeArg[dot+1:], isSlice)
 		}
 	}
 }
 
+// ParseStructsInPackage parses the struct named RequiredStruct, with its type arguments if any, ex:
+// Page[example.com/api.User], as a root, it and everything it refers to are marked as required
 func (c *Converter) ParseStructsInPackage(pkgPath, RequiredStruct string, IsSlice int) ParsedStruct {
+	rs := c.parseStructsInPackage(pkgPath, RequiredStruct, IsSlice)
+	if root := c.goTypeFromTypeString(pkgPath + "." + RequiredStruct); root != nil {
+		c.roots = append(c.roots, root)
+		c.markRequired()
+		rs.Required = c.Structs[pkgPath+"."+removeGenericsPartFromStructName(RequiredStruct)].Required
+	}
+	return rs
+}
+
+// parseStructsInPackage parses a struct the way ParseStructsInPackage does without making it a root
+func (c *Converter) parseStructsInPackage(pkgPath, RequiredStruct string, IsSlice int) ParsedStruct {
 	RequestedStruct := ParsedStruct{}
 	RequestedStruct.IsSlice = IsSlice
 	RequestedStruct.GenericPopulations = c.getGenericPopulations(RequiredStruct)
 	c.ensureGenericPopulations(RequiredStruct)
-	_, exists := c.AlreadyParsedPackage[pkgPath]
-	if exists {
-		rs := c.Structs[pkgPath+"."+removeGenericsPartFromStructName(RequiredStruct)]
-		rs.Required = true
-		c.Structs[pkgPath+"."+removeGenericsPartFromStructName(RequiredStruct)] = rs
-		rs.IsSlice = RequestedStruct.IsSlice
-		rs.GenericPopulations = RequestedStruct.GenericPopulations
-		return rs
+	if _, exists := c.AlreadyParsedPackage[pkgPath]; !exists {
+		c.parsePackage(pkgPath)
+	}
+	structID := pkgPath + "." + removeGenericsPartFromStructName(RequiredStruct)
+	if _, exists := c.structObjects[structID]; !exists {
+		return RequestedStruct
+	}
+	rs := c.parseNamedStruct(structID)
+	rs.IsSlice = RequestedStruct.IsSlice
+	rs.GenericPopulations = RequestedStruct.GenericPopulations
+	if len(rs.GenericPopulations) > 0 && !rs.Recursive {
+		instantiationName := c.GetInstantiationName(rs)
+		instance := rs
+		// whether the generic struct is required says nothing about this instantiation
+		instance.Required = c.Instantiations[instantiationName].Required
+		c.Instantiations[instantiationName] = instance
+		if c.instantiationTypes == nil {
+			c.instantiationTypes = map[string]string{}
+		}
+		c.instantiationTypes[instantiationName] = pkgPath + "." + RequiredStruct
+	}
+	return rs
+}
+
+// parsePackage loads the package at pkgPath and parses all the structs declared in it
+func (c *Converter) parsePackage(pkgPath string) {
+	if c.structObjects == nil {
+		c.structObjects = map[string]structObject{}
+	}
+	if c.parsingStructs == nil {
+		c.parsingStructs = map[string]bool{}
+	}
+	if c.Instantiations == nil {
+		c.Instantiations = map[string]ParsedStruct{}
+	}
+	if c.fset == nil {
+		c.fset = token.NewFileSet()
 	}
 	cfg := &packages.Config{
 		Mode:  packages.NeedTypes | packages.NeedName | packages.NeedTypesInfo | packages.NeedDeps | packages.NeedName | packages.NeedSyntax,
 		Tests: false,
+		Fset:  c.fset,
+		Dir:   c.Dir,
 	}
-	packages, _ := packages.Load(cfg, pkgPath)
+	pkgs, _ := packages.Load(cfg, pkgPath)
 	c.AlreadyParsedPackage[pkgPath] = true
-	for _, pkg := range packages {
-		docs, _ := doc.NewFromFiles(pkg.Fset, pkg.Syntax, "")
+	var structIDs []string
+	var aliases []*types.Alias
+	var interfaces []*types.Named
+	for _, pkg := range pkgs {
+		if c.loadedPackages == nil {
+			c.loadedPackages = map[string]*packages.Package{}
+		}
+		c.loadedPackages[pkg.PkgPath] = pkg
+		c.collectDirectives(pkgPath, pkg.Syntax)
+		// without PreserveAST go/doc strips the function bodies and unexported declarations out of the syntax
+		docs, _ := doc.NewFromFiles(pkg.Fset, pkg.Syntax, "", doc.PreserveAST)
 		for _, v := range docs.Types {
 			c.Docs[v.Name] = v.Doc
 		}
 		scope := pkg.Types.Scope()
 		for _, name := range scope.Names() {
-			obj := scope.Lookup(name)
-			switch item := obj.Type().Underlying().(type) {
+			obj, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok {
+				continue
+			}
+			if alias, isAlias := obj.Type().(*types.Alias); isAlias {
+				aliases = append(aliases, alias)
+				continue
+			}
+			switch obj.Type().Underlying().(type) {
 			case *types.Struct:
-				ObjectName := types.TypeString(obj.Type(), func(other *types.Package) string { return "" })
-				st, _ := item.Underlying().(*types.Struct)
-				parsedStruct := ParsedStruct{
-					Required:           isStructWithPackagePathEqualToRequiredStructName(RequiredStruct, obj.Type().String()),
-					PackageName:        pkg.Name,
-					PackgePath:         pkgPath,
-					ID:                 obj.Type().String(),
-					Name:               ObjectName,
-					GenericPopulations: c.getGenericPopulations(RequiredStruct),
-				}
-				for i := 0; i < st.NumFields(); i++ {
-					pf := ParsedField{
-						Var: st.Field(i),
-						Tag: st.Tag(i),
-					}
-					fieldName := pf.Var.Name()
-					typeName := pf.Var.Type().String()
-					FieldTypeName := pf.Var.Type().String()
-					if reflect.TypeOf(st.Field(i).Type()).String() == "*types.Slice" {
-						pf.IsSlice = countPrefixBrackets(FieldTypeName)
-					}
-					if strings.HasPrefix(typeName, "[]") {
-						typeName = strings.Replace(typeName, "[]", "", pf.IsSlice)
-					}
-					if strings.HasPrefix(typeName, "*") {
-						typeName = strings.Replace(typeName, "*", "", 1)
-					}
-					isTypeValid := false
-					if convertedTypeName, ok := GoTypeToTSType[typeName]; ok {
-						typeName = convertedTypeName
-						isTypeValid = true
-					}
-					typeNameSegments := strings.Split(typeName, ".")
-					if len(typeNameSegments) > 1 {
-						typeName = typeNameSegments[len(typeNameSegments)-1]
-					}
-					if pf.Tag != "" {
-						fieldTag := reflect.StructTag(pf.Tag)
-						jsonTag := fieldTag.Get("json")
-						if jsonTag != "" {
-							fieldName = strings.Split(jsonTag, ",")[0]
-						}
-						tsTypeTag := fieldTag.Get("ts_type")
-						if tsTypeTag != "" {
-							typeName = tsTypeTag
-							isTypeValid = true
-						}
-					}
-					pf.TSName = fieldName
-					pf.TSType = typeName
-					if pf.TSName != "-" {
-						if !isTypeValid && len(typeNameSegments) > 1 && !pf.Var.Embedded() {
-							if pf.IsSlice > 0 {
-								FieldTypeName = strings.Replace(FieldTypeName, "[]", "", pf.IsSlice)
-							}
-							DotSepFSPN := strings.Split(FieldTypeName, ".")
-							StructName := DotSepFSPN[len(DotSepFSPN)-1]
-							fieldPackagePath := strings.Replace(FieldTypeName, "."+StructName, "", 1)
-							refStruct := c.ParseStructsInPackage(fieldPackagePath, StructName, pf.IsSlice)
-							refStruct.Required = true
-							refStruct.IsSlice = pf.IsSlice
-							pf.RefStruct = refStruct
-						}
-						parsedStruct.Fields = append(parsedStruct.Fields, pf)
-					}
-				}
-				if parsedStruct.Required {
-					parsedStruct.IsSlice = RequestedStruct.IsSlice
-					RequestedStruct = parsedStruct
+				structID := pkgPath + "." + obj.Name()
+				c.structObjects[structID] = structObject{obj: obj, pkg: pkg}
+				structIDs = append(structIDs, structID)
+			case *types.Interface:
+				if named, ok := obj.Type().(*types.Named); ok {
+					interfaces = append(interfaces, named)
 				}
-				/*
-					a small workaround as we call this with in a package scan itself we could just ref this package
-					which is marked as already scaned but its not really scaned fully so we can invertatly set a struct
-					thats not parsed in this package as required and it would get overridden when it is parsed subsiquently
-					we just need to persist this to that as well
-				*/
-				fullStructNameAfterRemovingGenerics := removeGenericsPartFromStructName(obj.Type().String())
-				if oldVal, exists := c.Structs[fullStructNameAfterRemovingGenerics]; exists {
-					parsedStruct.Required = oldVal.Required
-				}
-				c.Structs[fullStructNameAfterRemovingGenerics] = parsedStruct
 			}
 		}
 	}
-	return RequestedStruct
+	// all the structs of the package are registered before parsing any so that fields referring
+	// to structs declared later in the package get them parsed on demand
+	for _, structID := range structIDs {
+		c.parseNamedStruct(structID)
+	}
+	for _, named := range interfaces {
+		c.parseUnion(named)
+	}
+	if c.EmitTypeAliases {
+		for _, alias := range aliases {
+			c.tsTypeFromAlias(alias)
+		}
+	}
 }
 
-func countPrefixBrackets(line string) int {
-	count := 0
-	prefix := "[]"
+// parseNamedStruct parses the struct registered as structID if it isn't already, a struct that is
+// referenced while it is still being parsed is a cycle in the type graph, such references get a
+// ParsedStruct with Recursive set and no fields, the full struct is found in Structs once parsed
+func (c *Converter) parseNamedStruct(structID string) ParsedStruct {
+	so := c.structObjects[structID]
+	parsedStruct := ParsedStruct{
+		PackageName: so.pkg.Name,
+		PackgePath:  so.pkg.PkgPath,
+		ID:          so.obj.Type().String(),
+		Name:        types.TypeString(so.obj.Type(), func(other *types.Package) string { return "" }),
+	}
+	if _, parsing := c.parsingStructs[structID]; parsing {
+		parsedStruct.Recursive = true
+		return parsedStruct
+	}
+	if ps, exists := c.Structs[structID]; exists {
+		return ps
+	}
+	c.parsingStructs[structID] = false
+	c.parseStack = append(c.parseStack, structID)
+	parsedStruct.Fields = c.parseStructFields(so.obj.Type().Underlying().(*types.Struct), so.obj.Name())
+	c.parseStack = c.parseStack[:len(c.parseStack)-1]
+	delete(c.parsingStructs, structID)
+	c.Structs[structID] = parsedStruct
+	return parsedStruct
+}
 
-	// Keep checking and removing the prefix "[]" from the start of the line
-	for strings.HasPrefix(line, prefix) {
-		count++
-		line = strings.TrimPrefix(line, prefix)
+// parseStructFields parses the fields of st, ownerName is the name of the struct declaring
+// them which is used to name the anonymous structs hoisted out of its fields
+func (c *Converter) parseStructFields(st *types.Struct, ownerName string) []ParsedField {
+	var fields []ParsedField
+	for i := 0; i < st.NumFields(); i++ {
+		pf := ParsedField{
+			Var: st.Field(i),
+			Tag: st.Tag(i),
+		}
+		if !c.IncludeUnexported && !isEncodedField(pf.Var) {
+			continue
+		}
+		fieldName := pf.Var.Name()
+		if c.FieldNaming != nil {
+			// tag names are used as is
+			fieldName = c.FieldNaming(fieldName)
+		}
+		elemType, isSlice := c.unwrapFieldType(pf.Var.Type())
+		pf.IsSlice = isSlice
+		typeName := c.tsTypeOf(elemType, ownerName+"_"+pf.Var.Name())
+		hasTSTypeTag := false
+		if pf.Tag != "" {
+			fieldTag := reflect.StructTag(pf.Tag)
+			nameTag := c.getNameTag(fieldTag)
+			tsTag := parseTSTag(fieldTag.Get("ts"))
+			if nameTag == "-" || tsTag.Skip {
+				continue
+			}
+			nameOptions := strings.Split(nameTag, ",")
+			if nameOptions[0] != "" {
+				fieldName = nameOptions[0]
+			}
+			pf.Optional = c.hasOptionalTag(fieldTag)
+			tsTypeTag := fieldTag.Get("ts_type")
+			if tsTypeTag != "" {
+				typeName = tsTypeTag
+				hasTSTypeTag = true
+			}
+			// the ts tag takes precedence over the others
+			if tsTag.Name != "" {
+				fieldName = tsTag.Name
+			}
+			if tsTag.Type != "" {
+				typeName = tsTag.Type
+				hasTSTypeTag = true
+			}
+			if tsTag.Optional != nil {
+				pf.Optional = *tsTag.Optional
+			}
+			pf.Readonly = tsTag.Readonly
+			pf.Nullable = tsTag.Nullable
+		}
+		pf.TSName = fieldName
+		pf.TSType = typeName
+		if !hasTSTypeTag && getEmbeddedStruct(pf) == nil {
+			pf.RefStruct = c.parseReferencedStructs(elemType, pf.IsSlice)
+			c.checkFieldType(pf, ownerName)
+		}
+		fields = append(fields, pf)
 	}
+	return fields
+}
 
-	return count
+// isEncodedField reports whether encoding/json encodes the field, unexported fields are skipped
+// except for embedded structs whose exported fields get promoted
+func isEncodedField(v *types.Var) bool {
+	if v.Exported() {
+		return true
+	}
+	if !v.Embedded() {
+		return false
+	}
+	t := v.Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	_, isStruct := t.Underlying().(*types.Struct)
+	return isStruct
 }
 
 func (c *Converter) getGenericPopulations(structName string) []ParsedField {
 	toRet := []ParsedField{}
-	arrayTrimmedStructName := strings.Trim(structName, "[]")
-	genericPopulations := strings.SplitN(arrayTrimmedStructName, "[", 2)
-	if len(genericPopulations) > 1 {
-		closingBracketTrimmed := strings.Trim(genericPopulations[1], "]")
-		for _, gp := range strings.Split(closingBracketTrimmed, ",") {
-			arrayTrimmed := strings.Trim(gp, "[]")
-			fullNameSegments := strings.Split(arrayTrimmed, ".")
-			isSlice := 0
-			if strings.HasPrefix(gp, "[]") {
-				isSlice = 1
-			}
-			fieldName := fullNameSegments[len(fullNameSegments)-1]
-			toRet = append(toRet, ParsedField{
-				IsSlice: isSlice,
-				TSType:  fieldName,
-			})
-		}
+	_, typeArgs := splitTypeArgs(structName)
+	for _, typeArg := range typeArgs {
+		isSlice := countPrefixBrackets(typeArg)
+		toRet = append(toRet, ParsedField{
+			IsSlice: isSlice,
+			TSType:  c.tsTypeFromTypeString(typeArg[2*isSlice:]),
+		})
 	}
 	return toRet
 }
 
-func isStructWithPackagePathEqualToRequiredStructName(RequiredStructName, PackagePath string) bool {
-	PackageStructSegments := strings.Split(PackagePath, ".")
-	return removeGenericsPartFromStructName(RequiredStructName) == removeGenericsPartFromStructName(PackageStructSegments[len(PackageStructSegments)-1])
+func countPrefixBrackets(line string) int {
+	count := 0
+	prefix := "[]"
+
+	// Keep checking and removing the prefix "[]" from the start of the line
+	for strings.HasPrefix(line, prefix) {
+		count++
+		line = strings.TrimPrefix(line, prefix)
+	}
+
+	return count
 }
 
 func removeGenericsPartFromStructName(name string) string {
@@ -259,38 +466,44 @@ func removeGenericsPartFromStructName(name string) string {
 }
 
 func (c *Converter) GetStructAsInterfaceString(ps ParsedStruct) string {
-	var toRet string = ""
-	doc, docExists := c.Docs[ps.Name]
-	if docExists {
-		if doc != "" {
-			toRet += c.GetFormattedTSComment(doc) + "\n"
+	var sb strings.Builder
+	c.WriteStructAsInterface(&sb, ps)
+	return sb.String()
+}
+
+// flattenEmbeddedFields replaces embedded struct fields, or pointers to structs, with the fields they
+// promote, other embedded types are fields of their own, ex: error
+func (c *Converter) flattenEmbeddedFields(fields []ParsedField) []ParsedField {
+	var flattened []ParsedField
+	for _, v := range fields {
+		named := getEmbeddedStruct(v)
+		if named == nil {
+			flattened = append(flattened, v)
+			continue
 		}
+		pkgPath := named.Obj().Pkg().Path()
+		ps := c.parseStructsInPackage(pkgPath, strings.TrimPrefix(types.TypeString(named, nil), pkgPath+"."), v.IsSlice)
+		ps = c.SetGenericPopulationsToFields(ps)
+		flattened = append(flattened, ps.Fields...)
 	}
-	if ps.Name == "" {
-		return ""
-	}
-	toRet += "export interface " + GetFormattedInterfaceName(ps.Name) + " {"
-	for _, v := range ps.Fields {
-		if v.Var.Embedded() {
-			pkgPath, structName := c.GetPackagePathAndStructNameFromFullDenotation(v.Var.Type().String())
-			ps := c.ParseStructsInPackage(pkgPath, structName, v.IsSlice)
-			ps = c.SetGenericPopulationsToFields(ps)
-			for _, f := range ps.Fields {
-				toRet += c.GetFieldAsString(f)
-			}
-			// embeddedPackageName := v.Var.Pkg().Path() + "." + v.TSName
-			// if strings.Contains(v.TSName, ".") {
-			// 	embeddedPackageName = v.TSName
-			// }
-			// for _, f := range c.Structs[embeddedPackageName].Fields {
-			// 	toRet += c.GetFieldAsString(f)
-			// }
-		} else {
-			toRet += c.GetFieldAsString(v)
-		}
+	return flattened
+}
+
+// getEmbeddedStruct returns the named struct an embedded field is, directly or via a pointer,
+// nil for the other fields
+func getEmbeddedStruct(pf ParsedField) *types.Named {
+	if pf.Var == nil || !pf.Var.Embedded() {
+		return nil
 	}
-	toRet += "\n}"
-	return toRet
+	embedded := types.Unalias(derefType(types.Unalias(pf.Var.Type())))
+	named, ok := embedded.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return nil
+	}
+	if _, isStruct := named.Underlying().(*types.Struct); !isStruct {
+		return nil
+	}
+	return named
 }
 
 func (c *Converter) GetPackagePathAndStructNameFromFullDenotation(fullPath string) (string, string) {
@@ -311,50 +524,58 @@ func (c *Converter) SetGenericPopulationsToFields(ps ParsedStruct) ParsedStruct
 	generics := strings.Replace(genericSegments[1], "]", "", 1)
 	genericParts := strings.Split(generics, ",")
 	for i, v := range genericParts {
+		if i >= len(ps.GenericPopulations) {
+			break
+		}
 		genericPartKey := strings.Split(strings.Trim(v, " "), " ")[0]
-		replaceMentMap[genericPartKey] = ps.GenericPopulations[i].TSType
+		replaceMentMap[genericPartKey] = getGenericPopulationTSType(ps.GenericPopulations[i])
 	}
 	for i, field := range ps.Fields {
-		typeTokens := strings.Split(field.TSType, "[")
-		var reconstructed []string
-		for _, tt := range typeTokens {
-			typeTokens2 := strings.Split(tt, "]")
-			var reconstructed2 []string
-			for _, tt2 := range typeTokens2 {
-				for k, v := range replaceMentMap {
-					if tt2 == k {
-						tt2 = v
-					}
-				}
-				reconstructed2 = append(reconstructed2, tt2)
-			}
-			reconstructed = append(reconstructed, strings.Join(reconstructed2, "]"))
-		}
-		ps.Fields[i].TSType = strings.Join(reconstructed, "[")
+		ps.Fields[i].TSType = replaceTSIdentifiers(field.TSType, replaceMentMap)
 	}
 	return ps
 }
 
-func (c *Converter) GetFieldAsString(pf ParsedField) string {
-	toRet := "\n" + c.Indent + pf.TSName
-
-	// Check if field should be optional
-	if pf.Tag != "" {
-		fieldTag := reflect.StructTag(pf.Tag)
-		jsonTag := fieldTag.Get("json")
-		optionalTag := fieldTag.Get("optional")
-
-		// Make field optional if it has omitempty or optional:"true"
-		if strings.Contains(jsonTag, ",omitempty") || optionalTag == "true" {
-			toRet += "?"
+// replaceTSIdentifiers swaps every identifier in a TS type expression that has an entry in replacements
+func replaceTSIdentifiers(TSType string, replacements map[string]string) string {
+	var result strings.Builder
+	identStart := -1
+	flush := func(end int) {
+		if identStart < 0 {
+			return
+		}
+		ident := TSType[identStart:end]
+		if replacement, ok := replacements[ident]; ok {
+			ident = replacement
 		}
+		result.WriteString(ident)
+		identStart = -1
 	}
-
-	toRet += ": " + c.postProcessTSTypeName(pf.TSType)
-	for i := 0; i < pf.IsSlice; i++ {
-		toRet += "[]"
+	for i, r := range TSType {
+		if r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if identStart < 0 {
+				identStart = i
+			}
+			continue
+		}
+		flush(i)
+		result.WriteRune(r)
 	}
-	return toRet
+	flush(len(TSType))
+	return result.String()
+}
+
+func (c *Converter) GetFieldAsString(pf ParsedField) string {
+	var sb strings.Builder
+	c.emitField(newEmitter(&sb), pf)
+	return sb.String()
+}
+
+// getFieldSignature renders a field as a TS property signature, ex: name?: string
+func (c *Converter) getFieldSignature(pf ParsedField) string {
+	var sb strings.Builder
+	c.emitFieldSignature(newEmitter(&sb), pf)
+	return sb.String()
 }
 
 func (c *Converter) postProcessTSTypeName(TSType string) string {
@@ -372,20 +593,18 @@ func (c *Converter) GetTSTypeFromMap(TSType string) string {
 	TSType = strings.Replace(TSType, "map[", "", 1)
 	TSTypeSegments := strings.Split(TSType, "]")
 	KeyType := TSTypeSegments[0]
-	if c.isMap(KeyType) {
-		KeyType = c.GetTSTypeFromMap(KeyType)
-	}
-	if convertedTypeName, ok := GoTypeToTSType[KeyType]; ok {
-		KeyType = convertedTypeName
+	// object keys are always strings in JSON, encoding/json formats integer keys as strings
+	if _, ok := c.TypeMappings[KeyType]; ok {
+		KeyType = "string"
 	}
 	ValueType := strings.Join(TSTypeSegments[1:], "]")
 	if c.isMap(ValueType) {
 		ValueType = c.GetTSTypeFromMap(ValueType)
 	}
-	if convertedTypeName, ok := GoTypeToTSType[ValueType]; ok {
+	if convertedTypeName, ok := c.TypeMappings[ValueType]; ok {
 		ValueType = convertedTypeName
 	}
-	return `{[key: ` + KeyType + `]: ` + ValueType + `}` + arrayIndication
+	return c.formatMapType(KeyType, ValueType, false) + arrayIndication
 }
 
 func (c *Converter) isMap(i string) bool {
@@ -399,14 +618,9 @@ func (c *Converter) isMap(i string) bool {
 }
 
 func (c *Converter) GetFormattedTSComment(commentContent string) string {
-	result := "\n/**\n"
-	for _, v := range strings.Split(commentContent, "\n") {
-		if v != "" {
-			result += c.Indent + v + "\n"
-		}
-	}
-	result += "*/"
-	return result
+	var sb strings.Builder
+	c.WriteFormattedTSComment(&sb, commentContent)
+	return sb.String()
 }
 
 func GetFormattedInterfaceName(name string) string {
@@ -0,0 +1,142 @@
+package gos2tsi
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"go/types"
+)
+
+// Diagnostic is a field that can't be converted faithfully, ex: its type has no meaningful TS equivalent
+type Diagnostic struct {
+	Pos token.Position
+	// StructID identifies the named struct being parsed when the field was found
+	StructID string
+	Struct   string
+	Field    string
+	GoType   string
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s.%s: %s", d.Pos, d.Struct, d.Field, d.Message)
+}
+
+// Err returns the diagnostics of the required structs as an error when Strict is set, every struct
+// of a package is parsed along with the required ones and those are not expected to be convertible
+func (c *Converter) Err() error {
+	if !c.Strict {
+		return nil
+	}
+	errs := []error{}
+	for _, d := range c.Diagnostics {
+		if c.Structs[d.StructID].Required {
+			errs = append(errs, errors.New(d.String()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkFieldType records a diagnostic if the type of the field can't be converted,
+// fields encoding/json skips aren't checked as they never make it into the JSON
+func (c *Converter) checkFieldType(pf ParsedField, ownerName string) {
+	if !isEncodedField(pf.Var) {
+		return
+	}
+	unsupported, message := c.findUnsupportedType(pf.Var.Type())
+	if unsupported == nil {
+		return
+	}
+	diagnostic := Diagnostic{
+		Struct:  ownerName,
+		Field:   pf.Var.Name(),
+		GoType:  types.TypeString(pf.Var.Type(), nil),
+		Message: message + " " + types.TypeString(unsupported, func(other *types.Package) string { return other.Name() }),
+	}
+	if len(c.parseStack) > 0 {
+		diagnostic.StructID = c.parseStack[len(c.parseStack)-1]
+	}
+	if c.fset != nil {
+		diagnostic.Pos = c.fset.Position(pf.Var.Pos())
+	}
+	c.addDiagnostic(diagnostic)
+}
+
+// addDiagnostic records a diagnostic unless it already was
+func (c *Converter) addDiagnostic(diagnostic Diagnostic) {
+	for _, d := range c.Diagnostics {
+		if d == diagnostic {
+			return
+		}
+	}
+	c.Diagnostics = append(c.Diagnostics, diagnostic)
+}
+
+// findUnsupportedType returns the first type with in t that has no TS equivalent, structs
+// are not looked into as their fields are checked when they are parsed
+func (c *Converter) findUnsupportedType(t types.Type) (types.Type, string) {
+	if _, ok := c.lookupTypeMapping(t); ok {
+		return nil, ""
+	}
+	switch item := t.(type) {
+	case *types.Alias:
+		return c.findUnsupportedType(types.Unalias(item))
+	case *types.Pointer:
+		return c.findUnsupportedType(item.Elem())
+	case *types.Slice:
+		return c.findUnsupportedType(item.Elem())
+	case *types.Array:
+		return c.findUnsupportedType(item.Elem())
+	case *types.Map:
+		if !isSupportedMapKey(item.Key()) {
+			return item.Key(), "unsupported map key type"
+		}
+		return c.findUnsupportedType(item.Elem())
+	case *types.Chan, *types.Signature:
+		return t, "unsupported type"
+	case *types.Basic:
+		if item.Info()&types.IsComplex != 0 || item.Kind() == types.UnsafePointer {
+			return t, "unsupported type"
+		}
+	case *types.Interface:
+		if !item.Empty() {
+			return t, "unsupported interface type"
+		}
+	case *types.Named:
+		for i := 0; i < item.TypeArgs().Len(); i++ {
+			if unsupported, message := c.findUnsupportedType(item.TypeArgs().At(i)); unsupported != nil {
+				return unsupported, message
+			}
+		}
+		switch underlying := item.Underlying().(type) {
+		case *types.Interface:
+			if item.Obj().Pkg() != nil {
+				if _, isUnion := c.Unions[item.Obj().Pkg().Path()+"."+item.Obj().Name()]; isUnion {
+					return nil, ""
+				}
+			}
+			if !underlying.Empty() {
+				return t, "unsupported interface type"
+			}
+		case *types.Struct:
+			return nil, ""
+		default:
+			if unsupported, _ := c.findUnsupportedType(underlying); unsupported != nil {
+				return t, "unsupported type"
+			}
+		}
+	}
+	return nil, ""
+}
+
+// isSupportedMapKey reports whether encoding/json can encode maps with keys of type key
+func isSupportedMapKey(key types.Type) bool {
+	key = types.Unalias(key)
+	if _, ok := key.(*types.TypeParam); ok {
+		return true
+	}
+	if basic, ok := key.Underlying().(*types.Basic); ok && basic.Info()&(types.IsString|types.IsInteger) != 0 {
+		return true
+	}
+	return isTextMarshaler(key)
+}
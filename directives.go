@@ -0,0 +1,151 @@
+package gos2tsi
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// directivePrefix marks the comment lines on type declarations that configure the converter,
+// ex: //gos2tsi:union discriminator=type
+const directivePrefix = "//gos2tsi:"
+
+// collectDirectives records the gos2tsi directives found in the doc comments of type declarations
+func (c *Converter) collectDirectives(pkgPath string, files []*ast.File) {
+	if c.directives == nil {
+		c.directives = map[string][]string{}
+	}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				docs := []*ast.CommentGroup{typeSpec.Doc}
+				if !genDecl.Lparen.IsValid() {
+					docs = append(docs, genDecl.Doc)
+				}
+				typeID := pkgPath + "." + typeSpec.Name.Name
+				for _, doc := range docs {
+					if doc == nil {
+						continue
+					}
+					for _, comment := range doc.List {
+						if strings.HasPrefix(comment.Text, directivePrefix) {
+							c.directives[typeID] = append(c.directives[typeID], strings.TrimPrefix(comment.Text, directivePrefix))
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// getDirective returns the arguments of the directive called name on the type typeID,
+// arguments are space separated key=value pairs, a key without a value is set to "true"
+func (c *Converter) getDirective(typeID, name string) (map[string]string, bool) {
+	for _, directive := range c.directives[typeID] {
+		fields := strings.Fields(directive)
+		if len(fields) == 0 || fields[0] != name {
+			continue
+		}
+		args := map[string]string{}
+		for _, arg := range fields[1:] {
+			key, value, hasValue := strings.Cut(arg, "=")
+			if !hasValue {
+				value = "true"
+			}
+			args[key] = value
+		}
+		return args, true
+	}
+	return nil, false
+}
+
+// getTypeName returns the TS name of the type declared as name in pkgPath, the one set with
+// //gos2tsi:export name=... or else the go name
+func (c *Converter) getTypeName(pkgPath, name string) string {
+	if args, ok := c.getDirective(pkgPath+"."+name, "export"); ok && args["name"] != "" {
+		return args["name"]
+	}
+	return name
+}
+
+func (c *Converter) getObjectTypeName(obj *types.TypeName) string {
+	if obj.Pkg() == nil {
+		return obj.Name()
+	}
+	return c.getTypeName(obj.Pkg().Path(), obj.Name())
+}
+
+// getStructTypeName returns the TS name of a parsed struct without its type parameters
+func (c *Converter) getStructTypeName(ps ParsedStruct) string {
+	return c.getTypeName(ps.PackgePath, removeGenericsPartFromStructName(ps.Name))
+}
+
+// getFormattedStructName returns the TS name of a parsed struct as GetFormattedInterfaceName does
+func (c *Converter) getFormattedStructName(ps ParsedStruct) string {
+	name := removeGenericsPartFromStructName(ps.Name)
+	return GetFormattedInterfaceName(c.getTypeName(ps.PackgePath, name) + strings.TrimPrefix(ps.Name, name))
+}
+
+// ParseExportedTypes loads the packages matching the patterns, ex: ./..., and parses the types
+// marked with a //gos2tsi:export directive as required, name=... sets the TS name of the type,
+// the exported structs are returned sorted by package and name, along with the errors of the
+// packages and of the patterns matching none
+func (c *Converter) ParseExportedTypes(patterns ...string) ([]ParsedStruct, error) {
+	exported := []ParsedStruct{}
+	var errs []error
+	seen := map[string]bool{}
+	for _, pattern := range patterns {
+		pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName, Dir: c.Dir}, pattern)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(pkgs) == 0 {
+			errs = append(errs, fmt.Errorf("pattern %s matched no packages", pattern))
+		}
+		for _, pkg := range pkgs {
+			if seen[pkg.PkgPath] {
+				continue
+			}
+			seen[pkg.PkgPath] = true
+			if len(pkg.Errors) == 0 {
+				if _, exists := c.AlreadyParsedPackage[pkg.PkgPath]; !exists {
+					c.parsePackage(pkg.PkgPath)
+				}
+				if loaded, exists := c.loadedPackages[pkg.PkgPath]; exists {
+					pkg = loaded
+				}
+			}
+			for _, pkgErr := range pkg.Errors {
+				errs = append(errs, pkgErr)
+			}
+			if len(pkg.Errors) > 0 || pkg.Types == nil {
+				continue
+			}
+			scope := pkg.Types.Scope()
+			for _, name := range scope.Names() {
+				obj, ok := scope.Lookup(name).(*types.TypeName)
+				if !ok {
+					continue
+				}
+				if _, marked := c.getDirective(pkg.PkgPath+"."+name, "export"); !marked {
+					continue
+				}
+				if ps := c.addRoot(obj.Type()); ps.Name != "" {
+					exported = append(exported, ps)
+				}
+			}
+		}
+	}
+	return exported, errors.Join(errs...)
+}
@@ -0,0 +1,184 @@
+package gos2tsi
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+)
+
+// emitter buffers the output written to an io.Writer, the first write error is kept and
+// every write after it is dropped so callers only check it once, on flush
+type emitter struct {
+	w io.StringWriter
+	// buf is nil when writing to a strings.Builder which needs no buffering
+	buf  *bufio.Writer
+	n    int64
+	err  error
+	last byte
+}
+
+func newEmitter(w io.Writer) *emitter {
+	if sb, ok := w.(*strings.Builder); ok {
+		return &emitter{w: sb}
+	}
+	buf := bufio.NewWriter(w)
+	return &emitter{w: buf, buf: buf}
+}
+
+func (e *emitter) str(s string) {
+	if e.err != nil || s == "" {
+		return
+	}
+	n, err := e.w.WriteString(s)
+	e.n += int64(n)
+	e.err = err
+	e.last = s[len(s)-1]
+}
+
+// line ends the current line unless the output already ends with one
+func (e *emitter) line() {
+	if e.n > 0 && e.last != '\n' {
+		e.str("\n")
+	}
+}
+
+func (e *emitter) flush() (int64, error) {
+	if e.err == nil && e.buf != nil {
+		e.err = e.buf.Flush()
+	}
+	return e.n, e.err
+}
+
+// WriteStructAsInterface writes the declaration GetStructAsInterfaceString returns to w
+func (c *Converter) WriteStructAsInterface(w io.Writer, ps ParsedStruct) (int64, error) {
+	e := newEmitter(w)
+	c.emitStruct(e, ps)
+	return e.flush()
+}
+
+// WriteFormattedTSComment writes the comment GetFormattedTSComment returns to w
+func (c *Converter) WriteFormattedTSComment(w io.Writer, commentContent string) (int64, error) {
+	e := newEmitter(w)
+	c.emitTSComment(e, commentContent)
+	return e.flush()
+}
+
+// WriteTo writes every declaration the converter collected to w, one after the other: the
+// required structs, the unions, the enums, the emitted type aliases and the generic instantiation aliases
+func (c *Converter) WriteTo(w io.Writer) (int64, error) {
+	e := newEmitter(w)
+	for _, id := range sortedKeys(c.Structs) {
+		if ps := c.Structs[id]; ps.Required {
+			c.emitStruct(e, ps)
+			e.line()
+		}
+	}
+	for _, id := range sortedKeys(c.Unions) {
+		if pu := c.Unions[id]; pu.Required {
+			e.str(c.GetUnionAsTypeString(pu))
+			e.line()
+		}
+	}
+	for _, id := range sortedKeys(c.Enums) {
+		if pe := c.Enums[id]; pe.Required {
+			e.str(c.GetEnumAsTypeString(pe))
+			e.line()
+		}
+	}
+	for _, id := range sortedKeys(c.TypeAliases) {
+		if pa := c.TypeAliases[id]; pa.Required {
+			e.str(c.GetTypeAliasString(pa))
+			e.line()
+		}
+	}
+	for _, name := range sortedKeys(c.Instantiations) {
+		if ps := c.Instantiations[name]; ps.Required {
+			e.str(c.GetInstantiationAliasString(ps))
+			e.line()
+		}
+	}
+	return e.flush()
+}
+
+func (c *Converter) emitStruct(e *emitter, ps ParsedStruct) {
+	if doc := c.Docs[removeGenericsPartFromStructName(ps.Name)]; doc != "" {
+		c.emitTSComment(e, doc)
+		e.str("\n")
+	}
+	if ps.Name == "" {
+		return
+	}
+	if c.Style.TypeDeclarations {
+		e.str(c.getDeclarationPrefix("type"))
+		e.str(c.getInterfaceName(ps))
+		e.str(" = {")
+	} else {
+		e.str(c.getDeclarationPrefix("interface"))
+		e.str(c.getInterfaceName(ps))
+		e.str(" {")
+	}
+	for _, pf := range c.flattenEmbeddedFields(ps.Fields) {
+		c.emitField(e, c.narrowDiscriminator(ps, pf))
+	}
+	e.str("\n}")
+	if c.Style.TrailingNewline {
+		e.str("\n")
+	}
+}
+
+func (c *Converter) emitField(e *emitter, pf ParsedField) {
+	e.str("\n")
+	e.str(c.Indent)
+	c.emitFieldSignature(e, pf)
+	e.str(c.Style.Terminator)
+}
+
+// emitFieldSignature writes a field as a TS property signature, ex: name?: string
+func (c *Converter) emitFieldSignature(e *emitter, pf ParsedField) {
+	if pf.Readonly || c.Style.Readonly {
+		e.str("readonly ")
+	}
+	e.str(c.getPropertyName(pf.TSName))
+	if c.isOptionalField(pf) {
+		e.str("?")
+	}
+	e.str(": ")
+	TSType := c.postProcessTSTypeName(pf.TSType)
+	nullable := pf.Nullable && (pf.IsSlice > 0 || !strings.HasSuffix(TSType, " | null"))
+	if c.Style.ReadonlyArrays || (pf.IsSlice > 0 && hasTopLevelUnion(TSType)) {
+		for i := 0; i < pf.IsSlice; i++ {
+			TSType = c.sliceOf(TSType)
+		}
+		e.str(TSType)
+	} else {
+		e.str(TSType)
+		for i := 0; i < pf.IsSlice; i++ {
+			e.str("[]")
+		}
+	}
+	if nullable {
+		e.str(" | null")
+	}
+}
+
+func (c *Converter) emitTSComment(e *emitter, commentContent string) {
+	e.str("\n/**\n")
+	for _, line := range strings.Split(commentContent, "\n") {
+		if line != "" {
+			e.str(c.Indent)
+			e.str(line)
+			e.str("\n")
+		}
+	}
+	e.str("*/")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,144 @@
+package gos2tsi
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ParsedEnum is a go type marked with //gos2tsi:enum, ex: type OrderStatus string, emitted as the
+// union of the values of its constants
+type ParsedEnum struct {
+	PackageName string
+	PackgePath  string
+	ID          string
+	Name        string
+	// Values are the values of the constants, ex: pending, quoted when emitted
+	Values []any
+	// Required is set for the enums the roots refer to, the ones that get output
+	Required bool
+}
+
+// tsTypeFromEnum refers to an enum by its name, adding its declaration to Enums
+func (c *Converter) tsTypeFromEnum(named *types.Named) string {
+	obj := named.Obj()
+	enumID := obj.Pkg().Path() + "." + obj.Name()
+	if _, exists := c.Enums[enumID]; !exists {
+		if c.Enums == nil {
+			c.Enums = map[string]ParsedEnum{}
+		}
+		parsedEnum := ParsedEnum{
+			PackageName: obj.Pkg().Name(),
+			PackgePath:  obj.Pkg().Path(),
+			ID:          enumID,
+			Name:        obj.Name(),
+		}
+		for _, value := range getDeclaredConstantValues(named) {
+			if !slices.Contains(parsedEnum.Values, value) {
+				parsedEnum.Values = append(parsedEnum.Values, value)
+			}
+		}
+		c.Enums[enumID] = parsedEnum
+	}
+	return c.getObjectTypeName(obj)
+}
+
+// isEnum reports whether named is a basic type marked with //gos2tsi:enum that has declared
+// constants, bitflags aren't as their combinations aren't among the declared values
+func (c *Converter) isEnum(named *types.Named) bool {
+	obj := named.Obj()
+	if obj.Pkg() == nil {
+		return false
+	}
+	if _, marked := c.getDirective(obj.Pkg().Path()+"."+obj.Name(), "enum"); !marked {
+		return false
+	}
+	_, isBasic := named.Underlying().(*types.Basic)
+	return isBasic && hasDeclaredConstants(named) && !c.isBitflag(named)
+}
+
+// isBitflag reports whether some constants of named are declared with a shift, ex: 1 << iota
+func (c *Converter) isBitflag(named *types.Named) bool {
+	pkg, exists := c.loadedPackages[named.Obj().Pkg().Path()]
+	if !exists || pkg.TypesInfo == nil {
+		return false
+	}
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.CONST {
+				continue
+			}
+			var values []ast.Expr
+			for _, spec := range genDecl.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				if len(valueSpec.Values) > 0 {
+					// the specs without values repeat the previous ones
+					values = valueSpec.Values
+				}
+				for _, name := range valueSpec.Names {
+					constObj, ok := pkg.TypesInfo.Defs[name].(*types.Const)
+					if ok && types.Identical(constObj.Type(), named) && slices.ContainsFunc(values, hasShift) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+func hasShift(expr ast.Expr) bool {
+	found := false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if binary, ok := n.(*ast.BinaryExpr); ok && binary.Op == token.SHL {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// getEnumZeroValue returns the zero value of an enum, cast when it isn't one of the declared values
+func (c *Converter) getEnumZeroValue(named *types.Named) (string, bool) {
+	name := c.tsTypeFromEnum(named)
+	zeroValue, ok := c.getBasicZeroValue(named.Underlying().(*types.Basic))
+	isDeclared := slices.ContainsFunc(c.Enums[named.Obj().Pkg().Path()+"."+named.Obj().Name()].Values, func(value any) bool {
+		return c.getLiteral(value) == zeroValue
+	})
+	if !ok || isDeclared {
+		return zeroValue, ok
+	}
+	return zeroValue + " as unknown as " + name, true
+}
+
+// GetEnumAsTypeString returns the TS union of the values of an enum,
+// ex: export type OrderStatus = "pending" | "paid", the values are in the order they are declared in
+func (c *Converter) GetEnumAsTypeString(pe ParsedEnum) string {
+	literals := []string{}
+	for _, value := range pe.Values {
+		literals = append(literals, c.getLiteral(value))
+	}
+	if len(literals) == 0 {
+		literals = []string{"never"}
+	}
+	return c.endDeclaration(c.getDeclarationPrefix("type") + c.getTypeName(pe.PackgePath, pe.Name) + " = " + strings.Join(literals, " | "))
+}
+
+// getLiteral returns the TS literal of a constant value, as getDeclaredConstantValues returns them
+func (c *Converter) getLiteral(value any) string {
+	switch v := value.(type) {
+	case string:
+		return c.quote(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
@@ -0,0 +1,12 @@
+//go:build go1.24
+
+package examplestructs
+
+type Set[T comparable] = map[T]struct{}
+
+type PageOf[T any] = Page[T]
+
+type StructWithGenericAliases struct {
+	Tags  Set[string]  `json:"tags"`
+	Users PageOf[User] `json:"users"`
+}
@@ -1,7 +1,12 @@
 package examplestructs
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
 	"time"
+	"unsafe"
 
 	"github.com/N4r35h/gos2tsi/exstructpkg2"
 	"github.com/N4r35h/gos2tsi/exstructpkg3"
@@ -26,6 +31,16 @@ type StructWithEmbeding struct {
 	Level string `json:"level"`
 }
 
+type StructWithEmbeddedPointer struct {
+	*EmbedableStruct
+	Level string `json:"level"`
+}
+
+type StructWithEmbeddedError struct {
+	error
+	Level string `json:"level"`
+}
+
 type SimpleStruct struct {
 	Test string `json:"test"`
 }
@@ -145,3 +160,371 @@ type StructWithPointers struct {
 	ArrayField *[]string                `json:"array_field"`
 	EntityX    *StructWithOptionalField `json:"entity_x"`
 }
+
+type StructWithMappedTypes struct {
+	CreatedAt time.Time                         `json:"created_at"`
+	History   []time.Time                       `json:"history"`
+	Deadlines map[string]*time.Time             `json:"deadlines"`
+	Page      SingleGenericStruct[SimpleStruct] `json:"page"`
+	External  exstructpkg2.SimpleStructPkg2     `json:"external"`
+}
+
+type StructWithArrays struct {
+	Coords   [2]float64      `json:"coords"`
+	Matrix   [3][3]int       `json:"matrix"`
+	Points   [][2]float64    `json:"points"`
+	Corners  [2]SimpleStruct `json:"corners"`
+	Checksum [4]byte         `json:"checksum"`
+	Samples  [6]uint16       `json:"samples"`
+}
+
+//gos2tsi:enum
+type OrderStatus string
+
+const (
+	OrderStatusPending OrderStatus = "pending"
+	OrderStatusPaid    OrderStatus = "paid"
+)
+
+type Label string
+
+// Permission is a bitflag, its values combine into ones that aren't declared
+//
+//gos2tsi:enum
+type Permission uint8
+
+const (
+	PermissionRead Permission = 1 << iota
+	PermissionWrite
+	PermissionAdmin
+)
+
+type StructWithEnums struct {
+	Status      OrderStatus   `json:"status"`
+	Permissions Permission    `json:"permissions"`
+	Timeout     time.Duration `json:"timeout"`
+	Month       time.Month    `json:"month"`
+	Mode        os.FileMode   `json:"mode"`
+}
+
+type Version int
+
+type Point struct {
+	X int
+	Y int
+}
+
+func (p Point) MarshalText() ([]byte, error) {
+	return []byte(strconv.Itoa(p.X) + "," + strconv.Itoa(p.Y)), nil
+}
+
+type StructWithMapKeys struct {
+	IntKeys     map[int]string                 `json:"int_keys"`
+	UintKeys    map[uint64]bool                `json:"uint_keys"`
+	NamedInt    map[Version]string             `json:"named_int_keys"`
+	Labels      map[Label]int                  `json:"labels"`
+	ByStatus    map[OrderStatus]float64        `json:"by_status"`
+	ByPoint     map[Point]string               `json:"by_point"`
+	NestedMaps  map[int]map[OrderStatus]string `json:"nested_maps"`
+	SliceValues map[string][]int               `json:"slice_values"`
+}
+
+type Node struct {
+	Name     string  `json:"name"`
+	Children []Node  `json:"children"`
+	Siblings []*Node `json:"siblings"`
+	Parent   *Node   `json:"parent"`
+}
+
+type Employee struct {
+	Name string `json:"name"`
+	Team *Team  `json:"team"`
+}
+
+type Team struct {
+	Lead    *Employee  `json:"lead"`
+	Members []Employee `json:"members"`
+}
+
+type StructWithNestedInlineStructs struct {
+	Meta struct {
+		Count  int    `json:"count"`
+		Cursor string `json:"cursor,omitempty"`
+		Hidden string `json:"-"`
+		Window struct {
+			From int `json:"from"`
+			To   int `json:"to"`
+		} `json:"window"`
+	} `json:"meta"`
+	Items []struct {
+		ID    uint          `json:"id"`
+		Owner *SimpleStruct `json:"owner" optional:"true"`
+	} `json:"items"`
+}
+
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+type ConstrainedGenericStruct[T any, K comparable, N ~int | ~float64, S interface{ ~string }, I ~int | ~string, M Number, P SimpleStruct, E fmt.Stringer] struct {
+	Items    []T `json:"items"`
+	Keys     []K `json:"keys"`
+	Total    N   `json:"total"`
+	Label    S   `json:"label"`
+	ID       I   `json:"id"`
+	Measure  M   `json:"measure"`
+	Payload  P   `json:"payload"`
+	Stringer E   `json:"stringer"`
+}
+
+type User struct {
+	Name string `json:"name"`
+}
+
+type Order struct {
+	ID uint `json:"id"`
+}
+
+type Page[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+}
+
+type Result[T any, E any] struct {
+	Value T `json:"value"`
+	Err   E `json:"err"`
+}
+
+type Pair[K comparable, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+type Wrapper[T any] struct {
+	Data T `json:"data"`
+}
+
+type Tree[T any] struct {
+	Value    T         `json:"value"`
+	Children []Tree[T] `json:"children"`
+}
+
+type StructWithGenericFields[K comparable, V any] struct {
+	Items  Page[User]                      `json:"items"`
+	Cache  map[string]Result[Order, error] `json:"cache"`
+	Nested Wrapper[[]Pair[K, V]]           `json:"nested"`
+	Pages  []Page[Pair[string, User]]      `json:"pages"`
+	Tree   Tree[V]                         `json:"tree"`
+}
+
+type UserAlias = User
+
+type RemoteStruct = exstructpkg2.SimpleStructPkg2
+
+type Labels = map[string]string
+
+type IDList = []uint
+
+type StructWithAliases struct {
+	Owner  UserAlias    `json:"owner"`
+	Remote RemoteStruct `json:"remote"`
+	Labels Labels       `json:"labels"`
+	IDs    IDList       `json:"ids"`
+}
+
+// Event is implemented by every event payload
+//
+//gos2tsi:union discriminator=type
+type Event interface {
+	isEvent()
+}
+
+type UserCreated struct {
+	Type string `json:"type"`
+	User User   `json:"user"`
+}
+
+func (UserCreated) isEvent() {}
+
+//gos2tsi:variant value=order_paid
+type OrderPaid struct {
+	Type   string  `json:"type"`
+	Amount float64 `json:"amount"`
+}
+
+func (*OrderPaid) isEvent() {}
+
+type EventEnvelope struct {
+	ID      string `json:"id"`
+	Payload Event  `json:"payload"`
+}
+
+type Shape interface {
+	Area() float64
+}
+
+type Circle struct {
+	Kind   string  `json:"kind"`
+	Radius float64 `json:"radius"`
+}
+
+func (c Circle) Area() float64 { return 3.14 * c.Radius * c.Radius }
+
+type Square struct {
+	Kind string  `json:"kind"`
+	Side float64 `json:"side"`
+}
+
+func (s Square) Area() float64 { return s.Side * s.Side }
+
+type Drawing struct {
+	Shapes []Shape `json:"shapes"`
+}
+
+type unexportedInterface interface {
+	do()
+}
+
+type StructWithUnsupportedTypes struct {
+	Name     string              `json:"name"`
+	Updates  chan int            `json:"updates"`
+	Callback func()              `json:"callback"`
+	Complex  complex128          `json:"complex"`
+	Raw      unsafe.Pointer      `json:"raw"`
+	Handler  unexportedInterface `json:"handler"`
+	Ratios   map[float64]string  `json:"ratios"`
+	Flags    map[bool]int        `json:"flags"`
+	Stringer fmt.Stringer        `json:"stringer"`
+	Err      error               `json:"err"`
+	Skipped  chan string         `json:"-"`
+	internal chan bool
+}
+
+type auditInfo struct {
+	CreatedBy string `json:"created_by"`
+}
+
+type StructWithUnexportedFields struct {
+	auditInfo
+	Name     string `json:"name"`
+	Password string `json:"password" ts:"-"`
+	Dash     string `json:"-,"`
+	Ignored  string `json:"-"`
+	secret   string
+	count    int
+}
+
+type StructWithTSTags struct {
+	UserID    int               `json:"user_id" ts:"name=userId"`
+	CreatedAt time.Time         `json:"created_at" ts:"type=string,readonly"`
+	Nickname  string            `json:"nickname,omitempty" ts:"optional=false"`
+	Email     *string           `json:"email" ts:"nullable,optional"`
+	Tags      []string          `json:"tags" ts:"nullable"`
+	Meta      map[string]string `ts:"type=Record<string, string>,readonly"`
+	Role      string            `json:"role" ts:"type='admin' | 'member'"`
+}
+
+type ListOrdersQuery struct {
+	Page     int    `form:"page" json:"page_number"`
+	PerPage  int    `query:"per_page,omitempty"`
+	Status   string `form:"status,omitempty" msgpack:"st"`
+	Internal string `form:"-" json:"internal"`
+	Sort     string
+}
+
+type StructWithFieldNames struct {
+	UserID         int
+	HTTPServer     string
+	FieldWOJSONTag string
+	Tagged         string `json:"Tagged_As_Is"`
+	ContentType    string `json:"content-type"`
+	DisplayName    string `json:"display name"`
+	Unicode        string `json:"naïve"`
+}
+
+type Profile struct {
+	Name        string            `json:"name"`
+	Tags        []string          `json:"tags"`
+	Location    [2]float64        `json:"location"`
+	Settings    map[string]string `json:"settings,omitempty"`
+	ContentType string            `json:"content-type"`
+}
+
+type WebSocketMessage struct {
+	ID       int            `json:"id"`
+	Active   bool           `json:"active"`
+	Grid     [][]float64    `json:"grid"`
+	Position [2]float64     `json:"position"`
+	Scores   map[string]int `json:"scores,omitempty"`
+	Sender   User           `json:"sender"`
+	ReplyTo  *User          `json:"reply_to" ts:"nullable"`
+	Page     Page[User]     `json:"page"`
+	Pairs    []Pair[string, Order]
+	Status   OrderStatus `json:"status"`
+	Payload  any         `json:"payload"`
+	SentAt   time.Time   `json:"sent_at" ts_type:"string"`
+	Event    Event       `json:"event"`
+}
+
+type CreateOrderRequest struct {
+	CustomerID int               `json:"customer_id"`
+	Note       string            `json:"note,omitempty"`
+	Items      []Order           `json:"items"`
+	Metadata   map[string]string `json:"metadata"`
+	Coupon     *string           `json:"coupon"`
+	Shipping   User              `json:"shipping,omitempty"`
+	Location   [2]float64        `json:"location"`
+	Urgent     bool              `json:"urgent,omitzero"`
+	Page       Page[User]        `json:"page"`
+}
+
+type StructWithEncodedTypes struct {
+	At     time.Time       `json:"at"`
+	Times  []*time.Time    `json:"times"`
+	Data   []byte          `json:"data"`
+	Where  Point           `json:"where"`
+	Raw    json.RawMessage `json:"raw"`
+	Chunks [][]byte        `json:"chunks"`
+}
+
+type Money struct {
+	Cents int64
+}
+
+func (m *Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(float64(m.Cents) / 100)
+}
+
+type SKU struct {
+	Vendor string
+	Code   int
+}
+
+func (s *SKU) MarshalText() ([]byte, error) {
+	return []byte(s.Vendor + "-" + strconv.Itoa(s.Code)), nil
+}
+
+type StructWithMarshalers struct {
+	Price    Money            `json:"price"`
+	Discount *Money           `json:"discount"`
+	SKU      SKU              `json:"sku"`
+	Related  []SKU            `json:"related"`
+	Prices   map[string]Money `json:"prices"`
+	Raw      json.RawMessage  `json:"raw"`
+	At       time.Time        `json:"at"`
+}
+
+// Account is picked up by ParseExportedTypes
+//
+//gos2tsi:export name=ApiAccount
+type Account struct {
+	ID       int             `json:"id"`
+	Owner    User            `json:"owner"`
+	Settings AccountSettings `json:"settings"`
+}
+
+//gos2tsi:export
+type AccountSettings struct {
+	Theme  string    `json:"theme"`
+	Linked []Account `json:"linked"`
+}
@@ -0,0 +1,57 @@
+package exhandlerpkg
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/N4r35h/gos2tsi/examplestructs"
+)
+
+type ErrorResponse struct {
+	Message string `json:"message"`
+}
+
+type Server struct {
+	orders []examplestructs.Order
+}
+
+func CreateOrder(w http.ResponseWriter, r *http.Request) {
+	var req examplestructs.CreateOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: err.Error()})
+		return
+	}
+	json.NewEncoder(w).Encode(&examplestructs.Order{ID: 1})
+}
+
+func (s *Server) ListOrders(w http.ResponseWriter, r *http.Request) {
+	enc := json.NewEncoder(w)
+	enc.Encode(examplestructs.Page[examplestructs.Order]{Items: s.orders, Total: len(s.orders)})
+}
+
+func GetUser(users map[string]examplestructs.User) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(users[r.PathValue("id")])
+	}
+}
+
+func Health(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ImportUser only decodes its request body, the other values go through other readers and writers
+func ImportUser(w http.ResponseWriter, r *http.Request) {
+	var origin examplestructs.Point
+	json.NewDecoder(strings.NewReader(r.FormValue("origin"))).Decode(&origin)
+	var user examplestructs.User
+	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	logger := json.NewEncoder(os.Stderr)
+	logger.Encode(examplestructs.Profile{})
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,18 @@
+package exunionpkg
+
+//gos2tsi:union discriminator=kind
+type Notice interface {
+	NoticeKind() string
+}
+
+//gos2tsi:variant value=local
+type Local struct {
+	Kind string `json:"kind"`
+	Text string `json:"text"`
+}
+
+func (l Local) NoticeKind() string { return l.Kind }
+
+type Envelope struct {
+	Notice Notice `json:"notice"`
+}
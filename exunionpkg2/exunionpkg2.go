@@ -0,0 +1,9 @@
+package exunionpkg2
+
+//gos2tsi:variant value=remote
+type Remote struct {
+	Kind string `json:"kind"`
+	URL  string `json:"url"`
+}
+
+func (r Remote) NoticeKind() string { return r.Kind }
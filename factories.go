@@ -0,0 +1,148 @@
+package gos2tsi
+
+import (
+	"go/types"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// NilSlicePolicy is how factories emit the nil slices and maps of go zero values
+type NilSlicePolicy int
+
+const (
+	// NilSlicesAsEmpty emits nil slices as [] and nil maps as {}
+	NilSlicesAsEmpty NilSlicePolicy = iota
+	// NilSlicesAsNull emits nil slices and maps as null, the way encoding/json encodes them
+	NilSlicesAsNull
+)
+
+// GetFactoryString returns a function creating the JSON shape of the zero value of a struct,
+// ex: export function newUser(): User, omitempty fields are left out like encoding/json does and
+// generic structs take a factory per type parameter: export function newPage<T>(newT: () => T): Page<T>
+func (c *Converter) GetFactoryString(ps ParsedStruct) string {
+	var sb strings.Builder
+	c.WriteFactory(&sb, ps)
+	return sb.String()
+}
+
+// WriteFactory writes the factory GetFactoryString returns to w
+func (c *Converter) WriteFactory(w io.Writer, ps ParsedStruct) (int64, error) {
+	e := newEmitter(w)
+	c.emitFactory(e, ps)
+	return e.flush()
+}
+
+// WriteFactories writes the factories of every required struct to w
+func (c *Converter) WriteFactories(w io.Writer) (int64, error) {
+	e := newEmitter(w)
+	for _, id := range sortedKeys(c.Structs) {
+		if ps := c.Structs[id]; ps.Required {
+			c.emitFactory(e, ps)
+			e.line()
+		}
+	}
+	return e.flush()
+}
+
+func (c *Converter) emitFactory(e *emitter, ps ParsedStruct) {
+	if ps.Name == "" {
+		return
+	}
+	name := c.getStructTypeName(ps)
+	typeParams, _ := c.getTypeGuardParams(ps)
+	factoryParams := []string{}
+	typeParamNames := c.getTypeParamNames(ps)
+	for _, typeParam := range typeParamNames {
+		factoryParams = append(factoryParams, getFactoryName(typeParam)+": () => "+typeParam)
+	}
+	e.str(c.getValueDeclarationPrefix("function"))
+	e.str(getFactoryName(name))
+	if len(typeParams) > 0 {
+		e.str("<" + strings.Join(typeParams, ", ") + ">")
+		name += "<" + strings.Join(typeParamNames, ", ") + ">"
+	}
+	e.str("(" + strings.Join(factoryParams, ", ") + "): " + name + " {\n")
+	e.str(c.Indent + "return {")
+	for _, pf := range c.flattenEmbeddedFields(ps.Fields) {
+		// only the fields the interface leaves optional, ex: not omitempty with ts:"optional=false"
+		if c.isOptionalField(pf) && isOmittedWhenZero(c.getNameTag(reflect.StructTag(pf.Tag)), pf.Var.Type()) {
+			continue
+		}
+		e.str("\n" + c.Indent + c.Indent + c.getPropertyName(pf.TSName) + ": " + c.getFieldZeroValue(ps, pf) + ",")
+	}
+	e.str("\n" + c.Indent + "}" + c.getStatementEnd() + "\n}")
+	if c.Style.TrailingNewline {
+		e.str("\n")
+	}
+}
+
+// getFieldZeroValue returns the TS expression of the zero value of the field of ps, cast to its
+// TS type when that doesn't allow it
+func (c *Converter) getFieldZeroValue(ps ParsedStruct, pf ParsedField) string {
+	TSType := c.postProcessTSTypeName(pf.TSType)
+	for i := 0; i < pf.IsSlice; i++ {
+		TSType = c.sliceOf(TSType)
+	}
+	if discriminator, isDiscriminator := c.getDiscriminator(ps, pf); isDiscriminator {
+		return c.quote(discriminator.Value)
+	}
+	if hasTypeOverride(pf) {
+		if zeroValue, ok := c.getTSTypeZeroValue(TSType); ok {
+			return zeroValue
+		}
+		// the zero value of the go type may not be one of the overriding type
+		if zeroValue, ok := c.getZeroValue(pf.Var.Type(), true, 0); ok {
+			return zeroValue + " as unknown as " + TSType
+		}
+	}
+	zeroValue, ok := c.getZeroValue(pf.Var.Type(), true, 0)
+	if !ok {
+		return "{} as " + TSType
+	}
+	if zeroValue == "null" && !pf.Nullable && TSType != "any" && TSType != "unknown" && !strings.HasSuffix(TSType, " | null") {
+		return "null as unknown as " + TSType
+	}
+	return zeroValue
+}
+
+// getFactoryFunc returns a factory for t, passed to the factories of generic structs
+func (c *Converter) getFactoryFunc(t types.Type, depth int) string {
+	switch item := t.(type) {
+	case *types.TypeParam:
+		return getFactoryName(item.Obj().Name())
+	case *types.Named:
+		if _, isStruct := item.Underlying().(*types.Struct); isStruct && item.TypeArgs().Len() == 0 {
+			return getFactoryName(c.getObjectTypeName(item.Obj()))
+		}
+	}
+	zeroValue, ok := c.getZeroValue(t, true, depth+1)
+	if !ok {
+		zeroValue = "{} as " + c.tsType(t)
+	}
+	return "(): " + c.tsType(t) + " => " + zeroValue
+}
+
+// isOmittedWhenZero reports whether encoding/json leaves out the zero value of a field of type t
+// given its name tag, omitzero always does while omitempty only does for empty values
+func isOmittedWhenZero(nameTag string, t types.Type) bool {
+	options := strings.Split(nameTag, ",")[1:]
+	if slices.Contains(options, "omitzero") {
+		return true
+	}
+	if !slices.Contains(options, "omitempty") {
+		return false
+	}
+	switch underlying := t.Underlying().(type) {
+	case *types.Struct:
+		return false
+	case *types.Array:
+		return underlying.Len() == 0
+	}
+	return true
+}
+
+func getFactoryName(name string) string {
+	return "new" + removeGenericsPartFromStructName(name)
+}
@@ -0,0 +1,219 @@
+package gos2tsi
+
+import (
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GetInstantiationName returns the name of the type alias emitted for a generic struct parsed
+// with its type arguments, ex: MultiGenericStruct_SimpleStruct_SimpleStruct1, or "" if ps isn't one
+func (c *Converter) GetInstantiationName(ps ParsedStruct) string {
+	if len(ps.GenericPopulations) == 0 {
+		return ""
+	}
+	return c.formatInstantiationName(c.getStructTypeName(ps), getGenericPopulationTSTypes(ps))
+}
+
+func (c *Converter) formatInstantiationName(name string, typeArgs []string) string {
+	if c.InstantiationNamer != nil {
+		return c.InstantiationNamer(name, typeArgs)
+	}
+	for _, typeArg := range typeArgs {
+		name += "_" + getInstantiationNamePart(typeArg)
+	}
+	return name
+}
+
+// GetInstantiationAliasString returns the type alias for a generic struct parsed with its type
+// arguments, ex: export type MultiGenericStruct_SimpleStruct_SimpleStruct1 = MultiGenericStruct<SimpleStruct, SimpleStruct1>
+func (c *Converter) GetInstantiationAliasString(ps ParsedStruct) string {
+	aliasName := c.GetInstantiationName(ps)
+	if aliasName == "" {
+		return ""
+	}
+	return c.endDeclaration(c.getDeclarationPrefix("type") + aliasName + " = " + c.getStructTypeName(ps) +
+		"<" + strings.Join(getGenericPopulationTSTypes(ps), ", ") + ">")
+}
+
+func getGenericPopulationTSTypes(ps ParsedStruct) []string {
+	typeArgs := []string{}
+	for _, gp := range ps.GenericPopulations {
+		typeArgs = append(typeArgs, getGenericPopulationTSType(gp))
+	}
+	return typeArgs
+}
+
+func getGenericPopulationTSType(gp ParsedField) string {
+	TSType := gp.TSType
+	for i := 0; i < gp.IsSlice; i++ {
+		TSType = arrayOf(TSType)
+	}
+	return TSType
+}
+
+// getInstantiationNamePart turns a TS type into something usable with in an identifier,
+// ex: Pair<string, number>[] as Pair_string_numberArray
+func getInstantiationNamePart(TSType string) string {
+	TSType = strings.ReplaceAll(TSType, "[]", "Array")
+	return strings.Join(strings.FieldsFunc(TSType, func(r rune) bool {
+		return r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "_")
+}
+
+// splitTypeArgs splits a type name as printed by reflect into its base name and type arguments,
+// ex: "pkg.Pair[pkg.Page[int],[]string]" into "pkg.Pair" and ["pkg.Page[int]", "[]string"]
+func splitTypeArgs(typeName string) (string, []string) {
+	start := -1
+	for i := 0; i < len(typeName); i++ {
+		if typeName[i] == '[' && i > 0 && typeName[i-1] != ']' && typeName[i-1] != '[' {
+			start = i
+			break
+		}
+	}
+	if start < 0 || !strings.HasSuffix(typeName, "]") {
+		return typeName, nil
+	}
+	typeArgs := []string{}
+	depth := 0
+	argStart := start + 1
+	for i := start + 1; i < len(typeName)-1; i++ {
+		switch typeName[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				typeArgs = append(typeArgs, strings.TrimSpace(typeName[argStart:i]))
+				argStart = i + 1
+			}
+		}
+	}
+	typeArgs = append(typeArgs, strings.TrimSpace(typeName[argStart:len(typeName)-1]))
+	return typeName[:start], typeArgs
+}
+
+// tsTypeFromTypeString renders a go type as printed by reflect as a TS type,
+// which is how the type arguments of instantiated structs are known
+func (c *Converter) tsTypeFromTypeString(goType string) string {
+	if tsType, ok := c.TypeMappings[goType]; ok {
+		return tsType
+	}
+	if tsType, ok := c.TypeMappings[strings.ReplaceAll(goType, ",", ", ")]; ok {
+		return tsType
+	}
+	switch {
+	case strings.HasPrefix(goType, "[]"):
+		return c.sliceOf(c.tsTypeFromTypeString(goType[2:]))
+	case strings.HasPrefix(goType, "*"):
+		return c.tsTypeFromTypeString(goType[1:])
+	case strings.HasPrefix(goType, "map["):
+		keyEnd := matchingBracket(goType, len("map"))
+		// object keys are always strings in JSON
+		return c.formatMapType("string", c.tsTypeFromTypeString(goType[keyEnd+1:]), false)
+	case strings.HasPrefix(goType, "["):
+		lenEnd := strings.Index(goType, "]")
+		length, _ := strconv.Atoi(goType[1:lenEnd])
+		elemType := c.tsTypeFromTypeString(goType[lenEnd+1:])
+		return c.tupleOf(elemType, int64(length))
+	}
+	baseName, typeArgs := splitTypeArgs(goType)
+	name := baseName[strings.LastIndex(baseName, ".")+1:]
+	if len(typeArgs) == 0 {
+		return name
+	}
+	tsTypeArgs := []string{}
+	for _, typeArg := range typeArgs {
+		tsTypeArgs = append(tsTypeArgs, c.tsTypeFromTypeString(typeArg))
+	}
+	return name + "<" + strings.Join(tsTypeArgs, ", ") + ">"
+}
+
+// matchingBracket returns the index of the bracket closing the one opened at open
+func matchingBracket(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(s) - 1
+}
+
+// goTypeFromTypeString resolves a type named the way reflect does, ex: Page[example.com/api.User],
+// loading the packages it refers to, nil if it can't be resolved
+func (c *Converter) goTypeFromTypeString(goType string) types.Type {
+	switch {
+	case strings.HasPrefix(goType, "[]"):
+		if elem := c.goTypeFromTypeString(goType[2:]); elem != nil {
+			return types.NewSlice(elem)
+		}
+		return nil
+	case strings.HasPrefix(goType, "*"):
+		if elem := c.goTypeFromTypeString(goType[1:]); elem != nil {
+			return types.NewPointer(elem)
+		}
+		return nil
+	case strings.HasPrefix(goType, "map["):
+		keyEnd := matchingBracket(goType, len("map"))
+		key := c.goTypeFromTypeString(goType[len("map["):keyEnd])
+		elem := c.goTypeFromTypeString(goType[keyEnd+1:])
+		if key == nil || elem == nil {
+			return nil
+		}
+		return types.NewMap(key, elem)
+	case strings.HasPrefix(goType, "["):
+		lenEnd := strings.Index(goType, "]")
+		length, _ := strconv.ParseInt(goType[1:lenEnd], 10, 64)
+		if elem := c.goTypeFromTypeString(goType[lenEnd+1:]); elem != nil {
+			return types.NewArray(elem, length)
+		}
+		return nil
+	case goType == "interface {}":
+		return types.Universe.Lookup("any").Type()
+	}
+	baseName, typeArgs := splitTypeArgs(goType)
+	dot := strings.LastIndex(baseName, ".")
+	if dot < 0 {
+		if obj, ok := types.Universe.Lookup(baseName).(*types.TypeName); ok {
+			return obj.Type()
+		}
+		return nil
+	}
+	pkgPath := baseName[:dot]
+	if _, exists := c.AlreadyParsedPackage[pkgPath]; !exists {
+		c.parsePackage(pkgPath)
+	}
+	pkg, exists := c.loadedPackages[pkgPath]
+	if !exists {
+		return nil
+	}
+	obj, ok := pkg.Types.Scope().Lookup(baseName[dot+1:]).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	if len(typeArgs) == 0 {
+		return obj.Type()
+	}
+	goTypeArgs := []types.Type{}
+	for _, typeArg := range typeArgs {
+		goTypeArg := c.goTypeFromTypeString(typeArg)
+		if goTypeArg == nil {
+			return nil
+		}
+		goTypeArgs = append(goTypeArgs, goTypeArg)
+	}
+	instance, err := types.Instantiate(nil, obj.Type(), goTypeArgs, false)
+	if err != nil {
+		return nil
+	}
+	return instance
+}
@@ -1,11 +1,13 @@
 module github.com/N4r35h/gos2tsi
 
-go 1.21.1
+go 1.23.0
 
 require (
-	github.com/sirupsen/logrus v1.9.3 // indirect
-	github.com/tompston/gut v0.0.4 // indirect
-	golang.org/x/mod v0.14.0 // indirect
-	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
-	golang.org/x/tools v0.16.0 // indirect
+	golang.org/x/tools v0.30.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	golang.org/x/mod v0.23.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
 )
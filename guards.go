@@ -0,0 +1,309 @@
+package gos2tsi
+
+import (
+	"go/types"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// GetTypeGuardString returns the runtime type guard of a struct, ex:
+// export function isUser(v: unknown): v is User, generic structs take a guard per type
+// parameter: export function isPage<T>(v: unknown, isT: (v: unknown) => v is T): v is Page<T>
+func (c *Converter) GetTypeGuardString(ps ParsedStruct) string {
+	var sb strings.Builder
+	c.WriteTypeGuard(&sb, ps)
+	return sb.String()
+}
+
+// WriteTypeGuard writes the type guard GetTypeGuardString returns to w
+func (c *Converter) WriteTypeGuard(w io.Writer, ps ParsedStruct) (int64, error) {
+	e := newEmitter(w)
+	c.emitTypeGuard(e, ps)
+	return e.flush()
+}
+
+// GetUnionTypeGuardString returns the type guard of a union, checking the guards of its members
+func (c *Converter) GetUnionTypeGuardString(pu ParsedUnion) string {
+	checks := []string{}
+	for _, member := range pu.Members {
+		checks = append(checks, getTypeGuardName(c.getStructTypeName(member))+"(v)")
+	}
+	if len(checks) == 0 {
+		checks = append(checks, "false")
+	}
+	name := c.getTypeName(pu.PackgePath, pu.Name)
+	return c.endDeclaration(c.getValueDeclarationPrefix("function") + getTypeGuardName(name) +
+		"(v: unknown): v is " + name + " {\n" +
+		c.Indent + "return " + strings.Join(checks, " || ") + c.getStatementEnd() + "\n}")
+}
+
+// WriteTypeGuards writes the type guards of every required struct and union to w
+func (c *Converter) WriteTypeGuards(w io.Writer) (int64, error) {
+	e := newEmitter(w)
+	for _, id := range sortedKeys(c.Structs) {
+		if ps := c.Structs[id]; ps.Required {
+			c.emitTypeGuard(e, ps)
+			e.line()
+		}
+	}
+	for _, id := range sortedKeys(c.Unions) {
+		if pu := c.Unions[id]; pu.Required {
+			e.str(c.GetUnionTypeGuardString(pu))
+			e.line()
+		}
+	}
+	return e.flush()
+}
+
+func (c *Converter) emitTypeGuard(e *emitter, ps ParsedStruct) {
+	if ps.Name == "" {
+		return
+	}
+	name := c.getStructTypeName(ps)
+	typeParams, guardParams := c.getTypeGuardParams(ps)
+	e.str(c.getValueDeclarationPrefix("function"))
+	e.str(getTypeGuardName(name))
+	if len(typeParams) > 0 {
+		e.str("<" + strings.Join(typeParams, ", ") + ">")
+	}
+	e.str("(" + strings.Join(append([]string{"v: unknown"}, guardParams...), ", ") + "): v is ")
+	e.str(name)
+	if len(typeParams) > 0 {
+		e.str("<" + strings.Join(c.getTypeParamNames(ps), ", ") + ">")
+	}
+	e.str(" {\n")
+	e.str(c.Indent + "if (" + c.getObjectCheck("v", true) + ") return false" + c.getStatementEnd() + "\n")
+	e.str(c.Indent + "const o = v as Record<string, unknown>" + c.getStatementEnd() + "\n")
+	checks := []string{}
+	for _, pf := range c.flattenEmbeddedFields(ps.Fields) {
+		if check := c.getFieldGuard(ps, pf); check != "" {
+			checks = append(checks, check)
+		}
+	}
+	if len(checks) == 0 {
+		checks = append(checks, "true")
+	}
+	e.str(c.Indent + "return " + strings.Join(checks, " &&\n"+c.Indent+c.Indent) + c.getStatementEnd() + "\n}")
+	if c.Style.TrailingNewline {
+		e.str("\n")
+	}
+}
+
+// getTypeGuardParams returns the type parameters of a generic struct, with their bounds, and
+// the guard parameters its type guard takes for each of them
+func (c *Converter) getTypeGuardParams(ps ParsedStruct) ([]string, []string) {
+	typeParams := []string{}
+	guardParams := []string{}
+	named := c.getStructNamed(ps)
+	if named == nil {
+		return typeParams, guardParams
+	}
+	for i := 0; i < named.TypeParams().Len(); i++ {
+		typeParam := named.TypeParams().At(i)
+		name := typeParam.Obj().Name()
+		formatted := name
+		if bound := c.tsTypeFromConstraint(typeParam.Constraint()); bound != "" {
+			formatted += " extends " + bound
+		}
+		typeParams = append(typeParams, formatted)
+		guardParams = append(guardParams, getTypeGuardName(name)+": (v: unknown) => v is "+name)
+	}
+	return typeParams, guardParams
+}
+
+func (c *Converter) getTypeParamNames(ps ParsedStruct) []string {
+	names := []string{}
+	if named := c.getStructNamed(ps); named != nil {
+		for i := 0; i < named.TypeParams().Len(); i++ {
+			names = append(names, named.TypeParams().At(i).Obj().Name())
+		}
+	}
+	return names
+}
+
+func (c *Converter) getStructNamed(ps ParsedStruct) *types.Named {
+	so, exists := c.structObjects[ps.PackgePath+"."+removeGenericsPartFromStructName(ps.Name)]
+	if !exists {
+		return nil
+	}
+	named, _ := so.obj.Type().(*types.Named)
+	return named
+}
+
+// getFieldGuard returns the condition checking the property of the field of ps on o, "" if any
+// value is accepted
+func (c *Converter) getFieldGuard(ps ParsedStruct, pf ParsedField) string {
+	access := "o." + pf.TSName
+	if !isTSIdentifier(pf.TSName) {
+		access = "o[" + c.quote(pf.TSName) + "]"
+	}
+	var check string
+	discriminator, isDiscriminator := c.getDiscriminator(ps, pf)
+	switch {
+	case isDiscriminator:
+		check = access + " === " + c.quote(discriminator.Value)
+	case hasTypeOverride(pf):
+		check = c.getArrayGuard(access, pf.IsSlice, 0, func(expr string, depth int) string {
+			return c.getTSTypeGuard(pf.TSType, expr)
+		})
+	default:
+		check = c.getTypeGuard(pf.Var.Type(), access, 0)
+	}
+	if check == "" {
+		return ""
+	}
+	if pf.Nullable {
+		check = "(" + access + " === null || " + check + ")"
+	}
+	if pf.Optional {
+		check = "(" + access + " === undefined || " + check + ")"
+	}
+	return check
+}
+
+// hasTypeOverride reports whether the TS type of the field was set via the ts_type or ts tags
+func hasTypeOverride(pf ParsedField) bool {
+	fieldTag := reflect.StructTag(pf.Tag)
+	return fieldTag.Get("ts_type") != "" || parseTSTag(fieldTag.Get("ts")).Type != ""
+}
+
+// getTypeGuard returns the condition checking that expr holds a t, "" if any value is accepted,
+// depth names the callback parameters of nested arrays and maps
+func (c *Converter) getTypeGuard(t types.Type, expr string, depth int) string {
+	if tsType, ok := c.lookupTypeMapping(t); ok {
+		return c.getTSTypeGuard(tsType, expr)
+	}
+	switch item := t.(type) {
+	case *types.Pointer:
+		check := c.getTypeGuard(item.Elem(), expr, depth)
+		if c.NullablePointers && check != "" {
+			return "(" + expr + " === null || " + check + ")"
+		}
+		return check
+	case *types.Slice:
+		if isByteSlice(item) {
+			return c.getTSTypeGuard("string", expr)
+		}
+		return c.getArrayGuard(expr, 1, depth, func(expr string, depth int) string {
+			return c.getTypeGuard(item.Elem(), expr, depth)
+		})
+	case *types.Array:
+		if basic, ok := item.Elem().Underlying().(*types.Basic); ok && basic.Kind() == types.Byte && c.ByteArraysAsString {
+			return c.getTSTypeGuard("string", expr)
+		}
+		param := "e" + strconv.Itoa(depth)
+		check := "Array.isArray(" + expr + ")"
+		if c.MaxTupleLength == 0 || item.Len() <= c.MaxTupleLength {
+			check += " && " + expr + ".length === " + strconv.FormatInt(item.Len(), 10)
+		}
+		if elemCheck := c.getTypeGuard(item.Elem(), param, depth+1); elemCheck != "" {
+			check += " && " + expr + ".every((" + param + ": unknown) => " + elemCheck + ")"
+		}
+		return check
+	case *types.Map:
+		param := "v" + strconv.Itoa(depth)
+		check := c.getObjectCheck(expr, false)
+		if valueCheck := c.getTypeGuard(item.Elem(), param, depth+1); valueCheck != "" {
+			check += " && Object.values(" + expr + " as object).every((" + param + ": unknown) => " + valueCheck + ")"
+		}
+		return check
+	case *types.Struct:
+		// the properties of inline object types aren't checked
+		return c.getObjectCheck(expr, false)
+	case *types.Named:
+		switch item.Underlying().(type) {
+		case *types.Struct:
+			args := []string{expr}
+			for i := 0; i < item.TypeArgs().Len(); i++ {
+				args = append(args, c.getGuardFunc(item.TypeArgs().At(i), depth))
+			}
+			return getTypeGuardName(c.getObjectTypeName(item.Obj())) + "(" + strings.Join(args, ", ") + ")"
+		case *types.Interface:
+			if item.Obj().Pkg() == nil {
+				return ""
+			}
+			if _, isUnion := c.Unions[item.Obj().Pkg().Path()+"."+item.Obj().Name()]; isUnion {
+				return getTypeGuardName(c.getObjectTypeName(item.Obj())) + "(" + expr + ")"
+			}
+			return ""
+		}
+		return c.getTypeGuard(item.Underlying(), expr, depth)
+	case *types.TypeParam:
+		return getTypeGuardName(item.Obj().Name()) + "(" + expr + ")"
+	case *types.Alias:
+		return c.getTypeGuard(types.Unalias(item), expr, depth)
+	case *types.Basic:
+		switch {
+		case item.Info()&types.IsString != 0:
+			return c.getTSTypeGuard("string", expr)
+		case item.Info()&types.IsNumeric != 0:
+			return c.getTSTypeGuard("number", expr)
+		case item.Info()&types.IsBoolean != 0:
+			return c.getTSTypeGuard("boolean", expr)
+		}
+	}
+	return ""
+}
+
+// getArrayGuard checks that expr is an array nested levels deep whose elements pass elemGuard
+func (c *Converter) getArrayGuard(expr string, levels, depth int, elemGuard func(expr string, depth int) string) string {
+	if levels == 0 {
+		return elemGuard(expr, depth)
+	}
+	param := "e" + strconv.Itoa(depth)
+	check := "Array.isArray(" + expr + ")"
+	if elemCheck := c.getArrayGuard(param, levels-1, depth+1, elemGuard); elemCheck != "" {
+		check += " && " + expr + ".every((" + param + ": unknown) => " + elemCheck + ")"
+	}
+	return check
+}
+
+// getGuardFunc returns a guard function for t, passed to the guards of generic structs
+func (c *Converter) getGuardFunc(t types.Type, depth int) string {
+	switch item := t.(type) {
+	case *types.TypeParam:
+		return getTypeGuardName(item.Obj().Name())
+	case *types.Named:
+		if _, isStruct := item.Underlying().(*types.Struct); isStruct && item.TypeArgs().Len() == 0 {
+			return getTypeGuardName(c.getObjectTypeName(item.Obj()))
+		}
+	}
+	param := "x" + strconv.Itoa(depth)
+	check := c.getTypeGuard(t, param, depth+1)
+	if check == "" {
+		check = "true"
+	}
+	return "(" + param + ": unknown): " + param + " is " + c.tsType(t) + " => " + check
+}
+
+// getTSTypeGuard checks expr against a TS type given as a string, only primitives are checked
+func (c *Converter) getTSTypeGuard(tsType, expr string) string {
+	switch tsType {
+	case "string", "number", "boolean":
+		return "typeof " + expr + " === " + c.quote(tsType)
+	case "null":
+		return expr + " === null"
+	}
+	return ""
+}
+
+// getObjectCheck checks that expr is a non array object, or that it isn't when negated
+func (c *Converter) getObjectCheck(expr string, negated bool) string {
+	if negated {
+		return "typeof " + expr + " !== " + c.quote("object") + " || " + expr + " === null || Array.isArray(" + expr + ")"
+	}
+	return "typeof " + expr + " === " + c.quote("object") + " && " + expr + " !== null && !Array.isArray(" + expr + ")"
+}
+
+func (c *Converter) getStatementEnd() string {
+	if c.Style.Terminator == ";" {
+		return ";"
+	}
+	return ""
+}
+
+func getTypeGuardName(name string) string {
+	return "is" + removeGenericsPartFromStructName(name)
+}
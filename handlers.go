@@ -0,0 +1,249 @@
+package gos2tsi
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// ParsedHandler is a net/http handler found by ParseHandlers with the TS types of the values it
+// decodes from the request body and encodes to the response
+type ParsedHandler struct {
+	// Name is the name of the handler, Type.Method for methods and Outer.funcN for function literals
+	Name        string
+	PackagePath string
+	Position    token.Position
+	// RequestTSType is the TS type of the value decoded from the request body, "" for none
+	RequestTSType string
+	// ResponseTSType is the TS type of the values encoded to the response, a union when there are
+	// several of them (ex: error responses), "" for none
+	ResponseTSType string
+}
+
+// ParseHandlers finds the net/http handlers, func(http.ResponseWriter, *http.Request), declared in
+// the package at pkgPath, including the function literals it returns or registers, the types they
+// decode from r.Body and encode to w via json.Decoder.Decode and json.Encoder.Encode are parsed as
+// required structs, values going through helper functions aren't followed
+func (c *Converter) ParseHandlers(pkgPath string) []ParsedHandler {
+	if _, exists := c.AlreadyParsedPackage[pkgPath]; !exists {
+		c.parsePackage(pkgPath)
+	}
+	handlers := []ParsedHandler{}
+	pkg, exists := c.loadedPackages[pkgPath]
+	if !exists || pkg.TypesInfo == nil {
+		return handlers
+	}
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Body == nil {
+				continue
+			}
+			fn, ok := pkg.TypesInfo.Defs[funcDecl.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			name := fn.Name()
+			signature := fn.Type().(*types.Signature)
+			if recv := signature.Recv(); recv != nil {
+				if named, ok := types.Unalias(derefType(recv.Type())).(*types.Named); ok {
+					name = named.Obj().Name() + "." + name
+				}
+			}
+			c.parseHandlerFunc(pkg, name, signature, funcDecl.Body, funcDecl.Pos(), &handlers)
+		}
+	}
+	slices.SortStableFunc(handlers, func(a, b ParsedHandler) int {
+		if a.Position.Filename != b.Position.Filename {
+			return strings.Compare(a.Position.Filename, b.Position.Filename)
+		}
+		return a.Position.Offset - b.Position.Offset
+	})
+	return handlers
+}
+
+// parseHandlerFunc adds the function to handlers if it is a handler, and does the same for the
+// function literals declared in its body
+func (c *Converter) parseHandlerFunc(pkg *packages.Package, name string, signature types.Type, body *ast.BlockStmt, pos token.Pos, handlers *[]ParsedHandler) {
+	isHandler := isHandlerSignature(signature)
+	var requests, responses []types.Type
+	var values map[*types.Var]ast.Expr
+	if isHandler {
+		values = getDefinedValues(pkg.TypesInfo, body)
+	}
+	literals := 0
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			literals++
+			c.parseHandlerFunc(pkg, name+".func"+strconv.Itoa(literals), pkg.TypesInfo.TypeOf(node), node.Body, node.Pos(), handlers)
+			return false
+		case *ast.CallExpr:
+			if !isHandler {
+				return true
+			}
+			method, arg := getJSONCodecCall(pkg.TypesInfo, node)
+			if method != "(*encoding/json.Decoder).Decode" && method != "(*encoding/json.Encoder).Encode" {
+				return true
+			}
+			params := signature.(*types.Signature).Params()
+			if !isHandlerCodec(pkg.TypesInfo, node.Fun.(*ast.SelectorExpr).X, params.At(0), params.At(1), values) {
+				// a decoder of something else than the request body or an encoder of something else
+				// than the response
+				return true
+			}
+			t := pkg.TypesInfo.TypeOf(arg)
+			if t == nil {
+				return true
+			}
+			t = derefType(t)
+			if _, isInterface := types.Unalias(t).(*types.Interface); isInterface {
+				// any, nothing is known about the value
+				return true
+			}
+			if method == "(*encoding/json.Decoder).Decode" {
+				requests = appendUniqueType(requests, t)
+			} else {
+				responses = appendUniqueType(responses, t)
+			}
+		}
+		return true
+	})
+	if !isHandler {
+		return
+	}
+	*handlers = append(*handlers, ParsedHandler{
+		Name:           name,
+		PackagePath:    pkg.PkgPath,
+		Position:       pkg.Fset.Position(pos),
+		RequestTSType:  c.getHandlerTSType(requests),
+		ResponseTSType: c.getHandlerTSType(responses),
+	})
+}
+
+// getHandlerTSType parses the structs the types refer to and returns the union of their TS types
+func (c *Converter) getHandlerTSType(ts []types.Type) string {
+	tsTypes := []string{}
+	for _, t := range ts {
+		c.addRoot(t)
+		if tsType := c.tsType(t); !slices.Contains(tsTypes, tsType) {
+			tsTypes = append(tsTypes, tsType)
+		}
+	}
+	return strings.Join(tsTypes, " | ")
+}
+
+// getJSONCodecCall returns the full name of the method called, ex: (*encoding/json.Decoder).Decode,
+// and its argument for the calls taking a single one, nil otherwise
+func getJSONCodecCall(info *types.Info, call *ast.CallExpr) (string, ast.Expr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) != 1 {
+		return "", nil
+	}
+	fn, ok := info.Uses[sel.Sel].(*types.Func)
+	if !ok {
+		return "", nil
+	}
+	return fn.FullName(), call.Args[0]
+}
+
+// isHandlerCodec reports whether codec, the receiver of Decode or Encode, is json.NewDecoder(r.Body)
+// or json.NewEncoder(w) where w and r are the parameters of the handler, directly or through a
+// variable defined as one of them
+func isHandlerCodec(info *types.Info, codec ast.Expr, w, r *types.Var, values map[*types.Var]ast.Expr) bool {
+	codec = ast.Unparen(codec)
+	if ident, ok := codec.(*ast.Ident); ok {
+		if v, ok := info.Uses[ident].(*types.Var); ok && values[v] != nil {
+			codec = ast.Unparen(values[v])
+		}
+	}
+	call, ok := codec.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	constructor, arg := getJSONCodecCall(info, call)
+	arg = ast.Unparen(arg)
+	switch constructor {
+	case "encoding/json.NewDecoder":
+		body, ok := arg.(*ast.SelectorExpr)
+		return ok && body.Sel.Name == "Body" && isParam(info, body.X, r)
+	case "encoding/json.NewEncoder":
+		return isParam(info, arg, w)
+	}
+	return false
+}
+
+// isParam reports whether expr refers to the parameter param
+func isParam(info *types.Info, expr ast.Expr, param *types.Var) bool {
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	return ok && param.Name() != "_" && info.Uses[ident] == param
+}
+
+// getDefinedValues returns the values of the variables defined in body with a single value each,
+// ex: enc := json.NewEncoder(w)
+func getDefinedValues(info *types.Info, body *ast.BlockStmt) map[*types.Var]ast.Expr {
+	values := map[*types.Var]ast.Expr{}
+	define := func(ident *ast.Ident, value ast.Expr) {
+		if v, ok := info.Defs[ident].(*types.Var); ok {
+			values[v] = value
+		}
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			if node.Tok != token.DEFINE || len(node.Lhs) != len(node.Rhs) {
+				return true
+			}
+			for i, lhs := range node.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok {
+					define(ident, node.Rhs[i])
+				}
+			}
+		case *ast.ValueSpec:
+			if len(node.Names) != len(node.Values) {
+				return true
+			}
+			for i, ident := range node.Names {
+				define(ident, node.Values[i])
+			}
+		}
+		return true
+	})
+	return values
+}
+
+// isHandlerSignature reports whether t is func(http.ResponseWriter, *http.Request)
+func isHandlerSignature(t types.Type) bool {
+	signature, ok := t.(*types.Signature)
+	if !ok || signature.Params().Len() != 2 || signature.Results().Len() != 0 {
+		return false
+	}
+	request, ok := signature.Params().At(1).Type().(*types.Pointer)
+	return ok && isNetHTTPType(signature.Params().At(0).Type(), "ResponseWriter") && isNetHTTPType(request.Elem(), "Request")
+}
+
+func isNetHTTPType(t types.Type, name string) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "net/http" && named.Obj().Name() == name
+}
+
+func derefType(t types.Type) types.Type {
+	if pointer, ok := types.Unalias(t).(*types.Pointer); ok {
+		return pointer.Elem()
+	}
+	return t
+}
+
+func appendUniqueType(ts []types.Type, t types.Type) []types.Type {
+	for _, existing := range ts {
+		if types.Identical(existing, t) {
+			return ts
+		}
+	}
+	return append(ts, t)
+}
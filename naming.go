@@ -0,0 +1,60 @@
+package gos2tsi
+
+import (
+	"strings"
+	"unicode"
+)
+
+// CamelCase is a FieldNaming policy, UserID becomes userID and HTTPServer becomes httpServer
+func CamelCase(name string) string {
+	runes := []rune(name)
+	for i := 0; i < len(runes) && unicode.IsUpper(runes[i]); i++ {
+		// the last capital of an initialism starts the next word
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// SnakeCase is a FieldNaming policy, UserID becomes user_id and HTTPServer becomes http_server
+func SnakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prev != '_' && (unicode.IsLower(prev) || unicode.IsDigit(prev) || nextIsLower) {
+				sb.WriteRune('_')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
+
+// getPropertyName quotes names that aren't valid TS identifiers
+func (c *Converter) getPropertyName(name string) string {
+	if isTSIdentifier(name) {
+		return name
+	}
+	return c.quote(name)
+}
+
+func isTSIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r == '_' || r == '$' || unicode.IsLetter(r) {
+			continue
+		}
+		if i > 0 && unicode.IsDigit(r) {
+			continue
+		}
+		return false
+	}
+	return true
+}
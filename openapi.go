@@ -0,0 +1,452 @@
+package gos2tsi
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/constant"
+	"go/types"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openAPISchemaRefPrefix is where the schemas of the components refer to each other
+const openAPISchemaRefPrefix = "#/components/schemas/"
+
+// OpenAPIDocument is an OpenAPI 3.1 document holding the schemas of the parsed types
+type OpenAPIDocument struct {
+	OpenAPI    string            `json:"openapi" yaml:"openapi"`
+	Info       OpenAPIInfo       `json:"info" yaml:"info"`
+	Components OpenAPIComponents `json:"components" yaml:"components"`
+}
+
+type OpenAPIInfo struct {
+	Title       string `json:"title" yaml:"title"`
+	Version     string `json:"version" yaml:"version"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+type OpenAPIComponents struct {
+	Schemas map[string]*OpenAPISchema `json:"schemas" yaml:"schemas"`
+}
+
+// OpenAPISchema is the subset of JSON Schema 2020-12 the converter emits
+type OpenAPISchema struct {
+	Ref                  string                `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Description          string                `json:"description,omitempty" yaml:"description,omitempty"`
+	Type                 OpenAPIType           `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string                `json:"format,omitempty" yaml:"format,omitempty"`
+	ContentEncoding      string                `json:"contentEncoding,omitempty" yaml:"contentEncoding,omitempty"`
+	Const                any                   `json:"const,omitempty" yaml:"const,omitempty"`
+	Enum                 []any                 `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Minimum              *int64                `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Properties           OpenAPIProperties     `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required             []string              `json:"required,omitempty" yaml:"required,omitempty"`
+	Items                *OpenAPISchema        `json:"items,omitempty" yaml:"items,omitempty"`
+	MinItems             *int64                `json:"minItems,omitempty" yaml:"minItems,omitempty"`
+	MaxItems             *int64                `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
+	AdditionalProperties *OpenAPISchema        `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	OneOf                []*OpenAPISchema      `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+	Discriminator        *OpenAPIDiscriminator `json:"discriminator,omitempty" yaml:"discriminator,omitempty"`
+	ReadOnly             bool                  `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
+}
+
+type OpenAPIDiscriminator struct {
+	PropertyName string            `json:"propertyName" yaml:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty" yaml:"mapping,omitempty"`
+}
+
+// OpenAPIType is the type keyword, written as a string unless it's nullable: [string, "null"]
+type OpenAPIType []string
+
+func (t OpenAPIType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t OpenAPIType) MarshalYAML() (any, error) {
+	if len(t) == 1 {
+		return t[0], nil
+	}
+	return []string(t), nil
+}
+
+// OpenAPIProperties are the properties of an object schema, kept in the order of the struct fields
+type OpenAPIProperties []OpenAPIProperty
+
+type OpenAPIProperty struct {
+	Name   string
+	Schema *OpenAPISchema
+}
+
+func (p OpenAPIProperties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, property := range p {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(property.Name)
+		buf.Write(name)
+		buf.WriteByte(':')
+		schema, err := json.Marshal(property.Schema)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(schema)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (p OpenAPIProperties) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, property := range p {
+		schema := &yaml.Node{}
+		if err := schema.Encode(property.Schema); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: property.Name}, schema)
+	}
+	return node, nil
+}
+
+// GetOpenAPIDocument returns the OpenAPI 3.1 document with a schema for every required struct,
+// union and generic instantiation, generic structs only get schemas for their instantiations
+func (c *Converter) GetOpenAPIDocument(info OpenAPIInfo) OpenAPIDocument {
+	return OpenAPIDocument{
+		OpenAPI:    "3.1.0",
+		Info:       info,
+		Components: c.GetOpenAPIComponents(),
+	}
+}
+
+// GetOpenAPIComponents returns the components.schemas of GetOpenAPIDocument
+func (c *Converter) GetOpenAPIComponents() OpenAPIComponents {
+	schemas := map[string]*OpenAPISchema{}
+	for _, id := range sortedKeys(c.Structs) {
+		ps := c.Structs[id]
+		if !ps.Required || ps.Name == "" {
+			continue
+		}
+		if named := c.getStructNamed(ps); named != nil && named.TypeParams().Len() > 0 {
+			continue
+		}
+		schemas[c.getStructTypeName(ps)] = c.getStructSchema(ps, nil, schemas)
+	}
+	for _, name := range sortedKeys(c.Instantiations) {
+		if !c.Instantiations[name].Required {
+			continue
+		}
+		if instance, ok := c.goTypeFromTypeString(c.instantiationTypes[name]).(*types.Named); ok {
+			c.addInstantiationSchema(name, c.Instantiations[name], instance, schemas)
+		}
+	}
+	for _, id := range sortedKeys(c.Unions) {
+		if !c.Unions[id].Required {
+			continue
+		}
+		schemas[c.getTypeName(c.Unions[id].PackgePath, c.Unions[id].Name)] = c.getUnionSchema(c.Unions[id])
+	}
+	return OpenAPIComponents{Schemas: schemas}
+}
+
+// WriteOpenAPIJSON writes the document GetOpenAPIDocument returns to w as indented JSON
+func (c *Converter) WriteOpenAPIJSON(w io.Writer, info OpenAPIInfo) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(c.GetOpenAPIDocument(info))
+}
+
+// WriteOpenAPIYAML writes the document GetOpenAPIDocument returns to w as YAML
+func (c *Converter) WriteOpenAPIYAML(w io.Writer, info OpenAPIInfo) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.GetOpenAPIDocument(info)); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// getStructSchema returns the object schema of a struct, instance is the instantiation the
+// fields of a generic struct get their types from
+func (c *Converter) getStructSchema(ps ParsedStruct, instance *types.Named, schemas map[string]*OpenAPISchema) *OpenAPISchema {
+	schema := &OpenAPISchema{
+		Type:        OpenAPIType{"object"},
+		Description: strings.TrimSpace(c.Docs[removeGenericsPartFromStructName(ps.Name)]),
+		Properties:  OpenAPIProperties{},
+	}
+	discriminator, hasDiscriminator := c.discriminators[ps.PackgePath+"."+removeGenericsPartFromStructName(ps.Name)]
+	for _, pf := range c.flattenEmbeddedFields(ps.Fields) {
+		var fieldSchema *OpenAPISchema
+		switch {
+		case hasDiscriminator && pf.TSName == discriminator.TSName:
+			fieldSchema = &OpenAPISchema{Type: OpenAPIType{"string"}, Const: discriminator.Value}
+		case hasTypeOverride(pf):
+			fieldSchema = c.getTSTypeSchema(pf.TSType)
+			for i := 0; i < pf.IsSlice; i++ {
+				fieldSchema = &OpenAPISchema{Type: OpenAPIType{"array"}, Items: fieldSchema}
+			}
+		default:
+			fieldSchema = c.getTypeSchema(getInstanceFieldType(pf, instance), schemas)
+		}
+		if pf.Nullable {
+			fieldSchema = nullableSchema(fieldSchema)
+		}
+		if pf.Readonly {
+			fieldSchema = readOnlySchema(fieldSchema)
+		}
+		schema.Properties = append(schema.Properties, OpenAPIProperty{Name: pf.TSName, Schema: fieldSchema})
+		if !pf.Optional {
+			schema.Required = append(schema.Required, pf.TSName)
+		}
+	}
+	return schema
+}
+
+// getInstanceFieldType returns the type of the field in the instantiation of its generic struct
+func getInstanceFieldType(pf ParsedField, instance *types.Named) types.Type {
+	if instance == nil {
+		return pf.Var.Type()
+	}
+	st, ok := instance.Underlying().(*types.Struct)
+	if !ok {
+		return pf.Var.Type()
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		if st.Field(i).Name() == pf.Var.Name() {
+			return st.Field(i).Type()
+		}
+	}
+	return pf.Var.Type()
+}
+
+// addInstantiationSchema materializes a generic struct instantiation as a concrete schema
+func (c *Converter) addInstantiationSchema(name string, ps ParsedStruct, instance *types.Named, schemas map[string]*OpenAPISchema) {
+	if _, exists := schemas[name]; exists {
+		return
+	}
+	// registered before the fields are converted so recursive instantiations refer to it
+	schemas[name] = &OpenAPISchema{}
+	*schemas[name] = *c.getStructSchema(ps, instance, schemas)
+}
+
+// getTypeSchema returns the schema of the JSON encoding of t
+func (c *Converter) getTypeSchema(t types.Type, schemas map[string]*OpenAPISchema) *OpenAPISchema {
+	// the default mappings of basic types are less precise than their schemas, ex: integers
+	if tsType, ok := c.lookupMappedType(t); ok && c.defaultTypeMappings[types.TypeString(t, nil)] != tsType {
+		return c.getTSTypeSchema(tsType)
+	}
+	if hasMarshaler(t, isJSONMarshaler) && !isTime(t) {
+		return &OpenAPISchema{}
+	}
+	if hasMarshaler(t, isTextMarshaler) && !isTime(t) {
+		return &OpenAPISchema{Type: OpenAPIType{"string"}}
+	}
+	switch item := t.(type) {
+	case *types.Pointer:
+		return nullableSchema(c.getTypeSchema(item.Elem(), schemas))
+	case *types.Slice:
+		if isByteSlice(item) {
+			// encoding/json encodes []byte as a base64 string
+			return &OpenAPISchema{Type: OpenAPIType{"string"}, ContentEncoding: "base64"}
+		}
+		return &OpenAPISchema{Type: OpenAPIType{"array"}, Items: c.getTypeSchema(item.Elem(), schemas)}
+	case *types.Array:
+		length := item.Len()
+		return &OpenAPISchema{
+			Type:     OpenAPIType{"array"},
+			Items:    c.getTypeSchema(item.Elem(), schemas),
+			MinItems: &length,
+			MaxItems: &length,
+		}
+	case *types.Map:
+		return &OpenAPISchema{Type: OpenAPIType{"object"}, AdditionalProperties: c.getTypeSchema(item.Elem(), schemas)}
+	case *types.Struct:
+		// the properties of inline structs aren't described
+		return &OpenAPISchema{Type: OpenAPIType{"object"}}
+	case *types.Named:
+		obj := item.Obj()
+		if isTime(item) {
+			return &OpenAPISchema{Type: OpenAPIType{"string"}, Format: "date-time"}
+		}
+		switch underlying := item.Underlying().(type) {
+		case *types.Struct:
+			if item.TypeArgs().Len() == 0 {
+				return &OpenAPISchema{Ref: openAPISchemaRefPrefix + c.getObjectTypeName(obj)}
+			}
+			name := c.getInstantiationNameOf(item)
+			if structID := obj.Pkg().Path() + "." + obj.Name(); c.structObjects[structID].obj != nil {
+				c.addInstantiationSchema(name, c.parseNamedStruct(structID), item, schemas)
+			}
+			return &OpenAPISchema{Ref: openAPISchemaRefPrefix + name}
+		case *types.Interface:
+			if obj.Pkg() != nil {
+				if _, isUnion := c.Unions[obj.Pkg().Path()+"."+obj.Name()]; isUnion {
+					return &OpenAPISchema{Ref: openAPISchemaRefPrefix + c.getObjectTypeName(obj)}
+				}
+			}
+			return &OpenAPISchema{}
+		case *types.Basic:
+			schema := c.getTypeSchema(underlying, schemas)
+			if c.isEnum(item) {
+				schema.Enum = getDeclaredConstantValues(item)
+			}
+			return schema
+		}
+		return c.getTypeSchema(item.Underlying(), schemas)
+	case *types.Alias:
+		return c.getTypeSchema(types.Unalias(item), schemas)
+	case *types.Basic:
+		switch {
+		case item.Info()&types.IsString != 0:
+			return &OpenAPISchema{Type: OpenAPIType{"string"}}
+		case item.Info()&types.IsInteger != 0:
+			schema := &OpenAPISchema{Type: OpenAPIType{"integer"}}
+			switch item.Kind() {
+			case types.Int32, types.Int16, types.Int8, types.Uint16, types.Uint8:
+				schema.Format = "int32"
+			case types.Int64:
+				schema.Format = "int64"
+			}
+			if item.Info()&types.IsUnsigned != 0 {
+				// the signed formats don't cover the range of uint32 and uint64
+				minimum := int64(0)
+				schema.Minimum = &minimum
+			}
+			return schema
+		case item.Info()&types.IsFloat != 0:
+			schema := &OpenAPISchema{Type: OpenAPIType{"number"}}
+			if item.Kind() == types.Float32 {
+				schema.Format = "float"
+			} else {
+				schema.Format = "double"
+			}
+			return schema
+		case item.Info()&types.IsBoolean != 0:
+			return &OpenAPISchema{Type: OpenAPIType{"boolean"}}
+		}
+	}
+	return &OpenAPISchema{}
+}
+
+// getInstantiationNameOf names a generic struct instantiation the way GetInstantiationName does
+func (c *Converter) getInstantiationNameOf(named *types.Named) string {
+	typeArgs := []string{}
+	for i := 0; i < named.TypeArgs().Len(); i++ {
+		typeArgs = append(typeArgs, c.tsType(named.TypeArgs().At(i)))
+	}
+	return c.formatInstantiationName(c.getObjectTypeName(named.Obj()), typeArgs)
+}
+
+// getTSTypeSchema returns the schema of a TS type given as a string, set via tags or type
+// mappings, only primitives and known structs are described
+func (c *Converter) getTSTypeSchema(tsType string) *OpenAPISchema {
+	switch {
+	case tsType == "string" || tsType == "number" || tsType == "boolean" || tsType == "null":
+		return &OpenAPISchema{Type: OpenAPIType{tsType}}
+	case strings.HasSuffix(tsType, " | null"):
+		return nullableSchema(c.getTSTypeSchema(strings.TrimSuffix(tsType, " | null")))
+	case strings.HasSuffix(tsType, "[]"):
+		return &OpenAPISchema{Type: OpenAPIType{"array"}, Items: c.getTSTypeSchema(strings.TrimSuffix(tsType, "[]"))}
+	}
+	for _, ps := range c.Structs {
+		if ps.Required && c.getStructTypeName(ps) == tsType {
+			return &OpenAPISchema{Ref: openAPISchemaRefPrefix + tsType}
+		}
+	}
+	return &OpenAPISchema{}
+}
+
+// getUnionSchema returns the oneOf schema of a union along with its discriminator, which is left
+// out unless every member has a variant value as the mapping can't tell the others apart
+func (c *Converter) getUnionSchema(pu ParsedUnion) *OpenAPISchema {
+	schema := &OpenAPISchema{Description: strings.TrimSpace(c.Docs[pu.Name]), OneOf: []*OpenAPISchema{}}
+	mapping := map[string]string{}
+	for _, member := range pu.Members {
+		ref := openAPISchemaRefPrefix + c.getStructTypeName(member)
+		schema.OneOf = append(schema.OneOf, &OpenAPISchema{Ref: ref})
+		if discriminator, ok := c.discriminators[member.PackgePath+"."+removeGenericsPartFromStructName(member.Name)]; ok {
+			mapping[discriminator.Value] = ref
+		}
+	}
+	if pu.Discriminator != "" && len(pu.Members) > 0 && len(mapping) == len(pu.Members) {
+		schema.Discriminator = &OpenAPIDiscriminator{PropertyName: pu.Discriminator, Mapping: mapping}
+	}
+	return schema
+}
+
+// nullableSchema adds null to the types a schema allows, refs are wrapped in a oneOf
+func nullableSchema(schema *OpenAPISchema) *OpenAPISchema {
+	if len(schema.Type) > 0 && schema.Ref == "" {
+		for _, t := range schema.Type {
+			if t == "null" {
+				return schema
+			}
+		}
+		nullable := *schema
+		nullable.Type = append(append(OpenAPIType{}, schema.Type...), "null")
+		return &nullable
+	}
+	if len(schema.Type) == 0 && schema.Ref == "" && len(schema.OneOf) == 0 {
+		// accepts anything already
+		return schema
+	}
+	return &OpenAPISchema{OneOf: []*OpenAPISchema{schema, {Type: OpenAPIType{"null"}}}}
+}
+
+// readOnlySchema returns a readOnly copy of a schema, OpenAPI 3.1 allows it next to $ref
+func readOnlySchema(schema *OpenAPISchema) *OpenAPISchema {
+	readOnly := *schema
+	readOnly.ReadOnly = true
+	return &readOnly
+}
+
+// getDeclaredConstantValues returns the values of the constants declared with the named type, in
+// the order they are declared in
+func getDeclaredConstantValues(named *types.Named) []any {
+	pkg := named.Obj().Pkg()
+	if pkg == nil {
+		return nil
+	}
+	constants := []*types.Const{}
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		if constObj, ok := scope.Lookup(name).(*types.Const); ok && types.Identical(constObj.Type(), named) {
+			constants = append(constants, constObj)
+		}
+	}
+	slices.SortFunc(constants, func(a, b *types.Const) int {
+		return int(a.Pos() - b.Pos())
+	})
+	values := []any{}
+	for _, constObj := range constants {
+		switch constObj.Val().Kind() {
+		case constant.String:
+			values = append(values, constant.StringVal(constObj.Val()))
+		case constant.Int:
+			if value, err := strconv.ParseInt(constObj.Val().ExactString(), 10, 64); err == nil {
+				values = append(values, value)
+			}
+		case constant.Float:
+			value, _ := constant.Float64Val(constObj.Val())
+			values = append(values, value)
+		case constant.Bool:
+			values = append(values, constant.BoolVal(constObj.Val()))
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+func isTime(t types.Type) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "time" && named.Obj().Name() == "Time"
+}
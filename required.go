@@ -0,0 +1,177 @@
+package gos2tsi
+
+import (
+	"go/types"
+	"reflect"
+)
+
+// addRoot parses the structs t refers to and makes t a root, see markRequired
+func (c *Converter) addRoot(t types.Type) ParsedStruct {
+	ps := c.parseReferencedStructs(t, 0)
+	c.roots = append(c.roots, t)
+	c.markRequired()
+	if ps.Name != "" {
+		ps.Required = c.Structs[ps.PackgePath+"."+removeGenericsPartFromStructName(ps.Name)].Required
+	}
+	return ps
+}
+
+// markRequired marks the structs, unions, enums, aliases and instantiations reachable from the roots as required,
+// and only those, whatever else got parsed along with the packages they are declared in isn't output, roots
+// are only ever added so it only walks the new ones and the structs that joined the required unions
+func (c *Converter) markRequired() {
+	if c.requiredVisited == nil {
+		c.requiredVisited = map[string]bool{}
+	}
+	// the structs of the packages loaded since may implement the unions
+	if len(c.structObjects) != c.markedStructs {
+		c.markedStructs = len(c.structObjects)
+		for _, id := range sortedKeys(c.Unions) {
+			c.updateUnionMembers(id)
+			if pu := c.Unions[id]; pu.Required {
+				for _, member := range pu.Members {
+					c.markRequiredStruct(member.PackgePath+"."+removeGenericsPartFromStructName(member.Name), c.requiredVisited)
+				}
+			}
+		}
+	}
+	for _, root := range c.roots[c.markedRoots:] {
+		c.markRequiredType(root, "", c.requiredVisited)
+	}
+	c.markedRoots = len(c.roots)
+}
+
+// markRequiredType marks what t refers to as required, inlineName is the name anonymous structs
+// are hoisted as, the same as for tsTypeOf
+func (c *Converter) markRequiredType(t types.Type, inlineName string, visited map[string]bool) {
+	if _, ok := c.lookupTypeMapping(t); ok {
+		return
+	}
+	switch item := t.(type) {
+	case *types.Alias:
+		if !c.EmitTypeAliases || item.Obj().Pkg() == nil {
+			c.markRequiredType(types.Unalias(item), inlineName, visited)
+			return
+		}
+		for i := 0; i < item.TypeArgs().Len(); i++ {
+			c.markRequiredType(item.TypeArgs().At(i), "", visited)
+		}
+		origin := item.Origin()
+		aliasID := origin.Obj().Pkg().Path() + "." + origin.Obj().Name()
+		pa, exists := c.TypeAliases[aliasID]
+		if !exists || visited[aliasID] {
+			return
+		}
+		visited[aliasID] = true
+		pa.Required = true
+		c.TypeAliases[aliasID] = pa
+		c.markRequiredType(origin.Rhs(), "", visited)
+	case *types.Pointer:
+		c.markRequiredType(item.Elem(), inlineName, visited)
+	case *types.Slice:
+		c.markRequiredType(item.Elem(), inlineName, visited)
+	case *types.Array:
+		c.markRequiredType(item.Elem(), inlineName, visited)
+	case *types.Map:
+		c.markRequiredType(item.Key(), "", visited)
+		c.markRequiredType(item.Elem(), inlineName, visited)
+	case *types.Struct:
+		if c.HoistInlineStructs && inlineName != "" && item.NumFields() > 0 {
+			c.markRequiredStruct(item.Field(0).Pkg().Path()+"."+inlineName, visited)
+			return
+		}
+		for i := 0; i < item.NumFields(); i++ {
+			pf := ParsedField{Var: item.Field(i), Tag: item.Tag(i)}
+			if !c.isSkippedField(pf) {
+				c.markRequiredType(pf.Var.Type(), inlineName+"_"+pf.Var.Name(), visited)
+			}
+		}
+	case *types.Named:
+		for i := 0; i < item.TypeArgs().Len(); i++ {
+			c.markRequiredType(item.TypeArgs().At(i), "", visited)
+		}
+		obj := item.Obj()
+		if obj.Pkg() == nil {
+			return
+		}
+		id := obj.Pkg().Path() + "." + obj.Name()
+		switch item.Underlying().(type) {
+		case *types.Basic:
+			if pe, isEnum := c.Enums[id]; isEnum {
+				pe.Required = true
+				c.Enums[id] = pe
+			}
+		case *types.Struct:
+			if item.TypeArgs().Len() > 0 {
+				if instance, exists := c.Instantiations[c.getInstantiationNameOf(item)]; exists {
+					instance.Required = true
+					c.Instantiations[c.getInstantiationNameOf(item)] = instance
+				}
+			}
+			c.markRequiredStruct(id, visited)
+		case *types.Interface:
+			pu, isUnion := c.Unions[id]
+			if !isUnion || visited[id] {
+				return
+			}
+			visited[id] = true
+			pu.Required = true
+			c.Unions[id] = pu
+			for _, member := range pu.Members {
+				c.markRequiredStruct(member.PackgePath+"."+removeGenericsPartFromStructName(member.Name), visited)
+			}
+		}
+	}
+}
+
+// markRequiredStruct marks a parsed struct and what its fields refer to as required
+func (c *Converter) markRequiredStruct(structID string, visited map[string]bool) {
+	ps, exists := c.Structs[structID]
+	if !exists || visited[structID] {
+		return
+	}
+	visited[structID] = true
+	ps.Required = true
+	c.Structs[structID] = ps
+	if named := c.getStructNamed(ps); named != nil {
+		for i := 0; i < named.TypeParams().Len(); i++ {
+			for _, term := range constraintTerms(named.TypeParams().At(i).Constraint()) {
+				c.markRequiredType(term, "", visited)
+			}
+		}
+	}
+	c.markRequiredFields(ps, visited)
+}
+
+// markRequiredFields marks what the fields of ps refer to as required, embedded structs aren't
+// output themselves, only the fields they promote
+func (c *Converter) markRequiredFields(ps ParsedStruct, visited map[string]bool) {
+	ownerName := removeGenericsPartFromStructName(ps.Name)
+	for _, pf := range ps.Fields {
+		if pf.Var == nil || hasTypeOverride(pf) {
+			continue
+		}
+		named := getEmbeddedStruct(pf)
+		if named == nil {
+			c.markRequiredType(pf.Var.Type(), ownerName+"_"+pf.Var.Name(), visited)
+			continue
+		}
+		for i := 0; i < named.TypeArgs().Len(); i++ {
+			c.markRequiredType(named.TypeArgs().At(i), "", visited)
+		}
+		embeddedID := named.Obj().Pkg().Path() + "." + named.Obj().Name()
+		if !visited["embedded "+embeddedID] {
+			visited["embedded "+embeddedID] = true
+			c.markRequiredFields(c.Structs[embeddedID], visited)
+		}
+	}
+}
+
+// isSkippedField reports whether the field of an anonymous struct is left out or has its type set via tags
+func (c *Converter) isSkippedField(pf ParsedField) bool {
+	if !c.IncludeUnexported && !isEncodedField(pf.Var) {
+		return true
+	}
+	fieldTag := reflect.StructTag(pf.Tag)
+	return c.getNameTag(fieldTag) == "-" || parseTSTag(fieldTag.Get("ts")).Skip || hasTypeOverride(pf)
+}
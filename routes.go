@@ -0,0 +1,250 @@
+package gos2tsi
+
+import (
+	"go/types"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pathParamPattern matches the parameters of path templates, written as {id} or as :id at the
+// start of a segment, the match of :id includes the / before it
+var pathParamPattern = regexp.MustCompile(`\{([^}/]+)\}|(?:^|/):([^/]+)`)
+
+// Route describes an API endpoint the client gets a method for, types are given as values
+// the same way as to ParseStruct, ex: Response: []User{}
+type Route struct {
+	// Name is the name of the client method, ex: getUser
+	Name   string
+	Method string
+	// Path is the path template, ex: /users/{id} or /users/:id
+	Path string
+	// PathParams is a struct naming the path parameters, nil types them as string | number
+	PathParams any
+	// Query is a struct with the query string parameters, nil for none
+	Query any
+	// Request is the request body, nil for none
+	Request any
+	// Response is the response body, nil for none
+	Response any
+}
+
+// ParsedRoute is a Route with the TS types of its parameters and bodies
+type ParsedRoute struct {
+	Route
+	PathParamNames   []string
+	PathParamsTSType string
+	QueryTSType      string
+	RequestTSType    string
+	ResponseTSType   string
+}
+
+// AddRoute registers a route for the client, parsing the types it refers to
+func (c *Converter) AddRoute(route Route) ParsedRoute {
+	pr := ParsedRoute{
+		Route:          route,
+		QueryTSType:    c.tsTypeOfValue(route.Query),
+		RequestTSType:  c.tsTypeOfValue(route.Request),
+		ResponseTSType: c.tsTypeOfValue(route.Response),
+	}
+	if pr.Method == "" {
+		pr.Method = "GET"
+	}
+	pr.Method = strings.ToUpper(pr.Method)
+	for _, match := range pathParamPattern.FindAllStringSubmatch(route.Path, -1) {
+		pr.PathParamNames = append(pr.PathParamNames, match[1]+match[2])
+	}
+	if route.PathParams != nil {
+		pr.PathParamsTSType = c.tsTypeOfValue(route.PathParams)
+	} else if len(pr.PathParamNames) > 0 {
+		params := []string{}
+		for _, name := range pr.PathParamNames {
+			params = append(params, c.getPropertyName(name)+": string | number")
+		}
+		pr.PathParamsTSType = c.braces(strings.Join(params, "; "))
+	}
+	c.Routes = append(c.Routes, pr)
+	return pr
+}
+
+// tsTypeOfValue returns the TS type of the type of v, parsing the structs it refers to, "" for nil
+func (c *Converter) tsTypeOfValue(v any) string {
+	if v == nil {
+		return ""
+	}
+	t := c.goTypeFromTypeString(getReflectTypeString(reflect.TypeOf(v)))
+	if t == nil {
+		return "unknown"
+	}
+	c.addRoot(t)
+	return c.tsType(t)
+}
+
+// getReflectTypeString names a type the way goTypeFromTypeString resolves it
+func getReflectTypeString(t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name()
+		}
+		return t.PkgPath() + "." + t.Name()
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return "*" + getReflectTypeString(t.Elem())
+	case reflect.Slice:
+		return "[]" + getReflectTypeString(t.Elem())
+	case reflect.Array:
+		return "[" + strconv.Itoa(t.Len()) + "]" + getReflectTypeString(t.Elem())
+	case reflect.Map:
+		return "map[" + getReflectTypeString(t.Key()) + "]" + getReflectTypeString(t.Elem())
+	}
+	return t.String()
+}
+
+// GetClientString returns the fetch based client of the registered routes, createClient takes
+// the base URL and optionally a fetch replacement, ex: createClient("https://example.com").getUser({id: 1}),
+// api is the client of the current origin: api.getUser({id: 1}): Promise<User>
+func (c *Converter) GetClientString() string {
+	var sb strings.Builder
+	e := newEmitter(&sb)
+	c.emitClient(e)
+	e.flush()
+	return sb.String()
+}
+
+// WriteClient writes every declaration the converter collected followed by the client of the
+// registered routes to w
+func (c *Converter) WriteClient(w io.Writer) (int64, error) {
+	n, err := c.WriteTo(w)
+	if err != nil {
+		return n, err
+	}
+	e := newEmitter(w)
+	if n > 0 {
+		e.str("\n")
+	}
+	c.emitClient(e)
+	m, err := e.flush()
+	return n + m, err
+}
+
+func (c *Converter) emitClient(e *emitter) {
+	indent := c.Indent
+	end := c.getStatementEnd()
+	e.str(c.getDeclarationPrefix("type") + "Fetcher = (input: string, init?: RequestInit) => Promise<Response>" + end + "\n\n")
+	e.str("async function request<T>(fetcher: Fetcher, method: string, url: string, query?: object, body?: unknown, parse = true): Promise<T> {\n")
+	e.str(indent + "if (query) {\n")
+	e.str(indent + indent + "const search = new URLSearchParams()" + end + "\n")
+	e.str(indent + indent + "for (const [key, value] of Object.entries(query)) {\n")
+	e.str(indent + indent + indent + "for (const item of Array.isArray(value) ? value : [value]) {\n")
+	e.str(indent + indent + indent + indent + "if (item !== undefined && item !== null) search.append(key, String(item))" + end + "\n")
+	e.str(indent + indent + indent + "}\n")
+	e.str(indent + indent + "}\n")
+	e.str(indent + indent + "if (search.size > 0) url += " + c.quote("?") + " + search.toString()" + end + "\n")
+	e.str(indent + "}\n")
+	e.str(indent + "const res = await fetcher(url, {\n")
+	e.str(indent + indent + "method,\n")
+	e.str(indent + indent + "headers: body === undefined ? undefined : " + c.braces(c.quote("Content-Type")+": "+c.quote("application/json")) + ",\n")
+	e.str(indent + indent + "body: body === undefined ? undefined : JSON.stringify(body),\n")
+	e.str(indent + "})" + end + "\n")
+	e.str(indent + "if (!res.ok) throw new Error(`${method} ${url}: ${res.status}`)" + end + "\n")
+	e.str(indent + "const text = await res.text()" + end + "\n")
+	e.str(indent + "if (!parse || text === " + c.quote("") + ") return undefined as T" + end + "\n")
+	e.str(indent + "return JSON.parse(text) as T" + end + "\n")
+	e.str("}\n\n")
+	e.str(c.getValueDeclarationPrefix("function") + "createClient(baseURL = " + c.quote("") + ", fetcher: Fetcher = (input, init) => fetch(input, init)) {\n")
+	e.str(indent + "return {")
+	for _, route := range c.Routes {
+		e.str("\n" + indent + indent + c.getClientMethod(route) + ",")
+	}
+	e.str("\n" + indent + "}" + end + "\n}\n\n")
+	e.str(c.getValueDeclarationPrefix("const") + "api = createClient()" + end)
+	if c.Style.TrailingNewline {
+		e.str("\n")
+	}
+}
+
+// getClientMethod returns the client method of a route, taking the path parameters, the query
+// and the request body, in that order, for the ones the route has
+func (c *Converter) getClientMethod(route ParsedRoute) string {
+	params := []string{}
+	if route.PathParamsTSType != "" {
+		params = append(params, "params: "+route.PathParamsTSType)
+	}
+	if route.QueryTSType != "" {
+		params = append(params, "query: "+route.QueryTSType)
+	}
+	if route.RequestTSType != "" {
+		params = append(params, "body: "+route.RequestTSType)
+	}
+	responseType := route.ResponseTSType
+	if responseType == "" {
+		responseType = "void"
+	}
+	args := []string{"fetcher", c.quote(route.Method), "baseURL + " + c.getClientURL(route)}
+	switch {
+	case route.RequestTSType != "" && route.QueryTSType != "":
+		args = append(args, "query", "body")
+	case route.RequestTSType != "":
+		args = append(args, "undefined", "body")
+	case route.QueryTSType != "":
+		args = append(args, "query")
+	}
+	if route.ResponseTSType == "" {
+		// the body of routes without a response isn't parsed, whatever it holds
+		for len(args) < 5 {
+			args = append(args, "undefined")
+		}
+		args = append(args, "false")
+	}
+	return c.getPropertyName(route.Name) + ": (" + strings.Join(params, ", ") + "): Promise<" + responseType + "> =>\n" +
+		c.Indent + c.Indent + c.Indent + "request<" + responseType + ">(" + strings.Join(args, ", ") + ")"
+}
+
+// getClientURL returns the path of a route as a TS expression, path parameters are interpolated
+func (c *Converter) getClientURL(route ParsedRoute) string {
+	if len(route.PathParamNames) == 0 {
+		return c.quote(route.Path)
+	}
+	fieldNames := c.getPathParamFieldNames(route)
+	i := 0
+	url := pathParamPattern.ReplaceAllStringFunc(strings.ReplaceAll(route.Path, "`", "\\`"), func(match string) string {
+		access := "params." + fieldNames[i]
+		if !isTSIdentifier(fieldNames[i]) {
+			access = "params[" + c.quote(fieldNames[i]) + "]"
+		}
+		i++
+		separator := ""
+		if strings.HasPrefix(match, "/") {
+			separator = "/"
+		}
+		return separator + "${encodeURIComponent(String(" + access + "))}"
+	})
+	return "`" + url + "`"
+}
+
+// getPathParamFieldNames returns the properties of the params argument holding each path parameter,
+// the ones of a PathParams struct are matched by their TS name or, ignoring case, their go name
+func (c *Converter) getPathParamFieldNames(route ParsedRoute) []string {
+	fieldNames := append([]string{}, route.PathParamNames...)
+	if route.PathParams == nil {
+		return fieldNames
+	}
+	t := c.goTypeFromTypeString(getReflectTypeString(reflect.TypeOf(route.PathParams)))
+	named, ok := t.(*types.Named)
+	if !ok {
+		return fieldNames
+	}
+	ps := c.Structs[named.Obj().Pkg().Path()+"."+named.Obj().Name()]
+	for i, name := range fieldNames {
+		for _, pf := range c.flattenEmbeddedFields(ps.Fields) {
+			if pf.TSName == name || strings.EqualFold(pf.Var.Name(), name) {
+				fieldNames[i] = pf.TSName
+				break
+			}
+		}
+	}
+	return fieldNames
+}
@@ -0,0 +1,85 @@
+package gos2tsi
+
+import (
+	"strconv"
+	"strings"
+)
+
+// OutputStyle configures how declarations are written, the zero value gives the default
+// output: export interface X { with one unterminated property per line
+type OutputStyle struct {
+	// TypeDeclarations emits structs as export type X = {...} instead of interfaces
+	TypeDeclarations bool `json:"type_declarations" yaml:"type_declarations"`
+	// Terminator is written after every property, ex: ";" or ","
+	Terminator string `json:"terminator" yaml:"terminator"`
+	// SingleQuotes quotes property names and string literals with ' instead of "
+	SingleQuotes bool `json:"single_quotes" yaml:"single_quotes"`
+	// Readonly marks every property readonly
+	Readonly bool `json:"readonly" yaml:"readonly"`
+	// ReadonlyArrays emits slices as ReadonlyArray<T> and tuples as readonly [T, U]
+	ReadonlyArrays bool `json:"readonly_arrays" yaml:"readonly_arrays"`
+	// NoExport leaves out the export keyword
+	NoExport bool `json:"no_export" yaml:"no_export"`
+	// Declare emits ambient declarations, ex: export declare interface X {, the declarations with
+	// bodies or values, ex: the type guards, classes, factories and client, never are
+	Declare bool `json:"declare" yaml:"declare"`
+	// TrailingNewline ends every declaration with a newline
+	TrailingNewline bool `json:"trailing_newline" yaml:"trailing_newline"`
+	// BracketSpacing pads the braces of inline object types and literals, ex: { [key: string]: T }
+	BracketSpacing bool `json:"bracket_spacing" yaml:"bracket_spacing"`
+}
+
+// getDeclarationPrefix returns the keywords in front of a declaration of the given kind
+func (c *Converter) getDeclarationPrefix(kind string) string {
+	prefix := ""
+	if !c.Style.NoExport {
+		prefix += "export "
+	}
+	if c.Style.Declare {
+		prefix += "declare "
+	}
+	return prefix + kind + " "
+}
+
+// getValueDeclarationPrefix returns the keywords in front of a declaration with a body, ex: a
+// function, which can't be ambient whatever the style
+func (c *Converter) getValueDeclarationPrefix(kind string) string {
+	if c.Style.NoExport {
+		return kind + " "
+	}
+	return "export " + kind + " "
+}
+
+// endDeclaration appends the trailing newline to a declaration if the style asks for one
+func (c *Converter) endDeclaration(declaration string) string {
+	if c.Style.TrailingNewline && declaration != "" {
+		return declaration + "\n"
+	}
+	return declaration
+}
+
+// quote returns s as a TS string literal
+func (c *Converter) quote(s string) string {
+	quoted := strconv.Quote(s)
+	if !c.Style.SingleQuotes {
+		return quoted
+	}
+	quoted = strings.ReplaceAll(quoted[1:len(quoted)-1], `\"`, `"`)
+	return "'" + strings.ReplaceAll(quoted, "'", `\'`) + "'"
+}
+
+// braces wraps the members of an inline object type or literal in braces
+func (c *Converter) braces(members string) string {
+	if c.Style.BracketSpacing && members != "" {
+		return "{ " + members + " }"
+	}
+	return "{" + members + "}"
+}
+
+// sliceOf returns the TS type of a slice of elemType
+func (c *Converter) sliceOf(elemType string) string {
+	if c.Style.ReadonlyArrays {
+		return "ReadonlyArray<" + elemType + ">"
+	}
+	return arrayOf(elemType)
+}
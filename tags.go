@@ -0,0 +1,114 @@
+package gos2tsi
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// TSTag is the parsed ts struct tag, ex: ts:"name=userId,type=string,optional,readonly,nullable"
+type TSTag struct {
+	// Skip is set by ts:"-", the field is left out of the TS output only
+	Skip     bool
+	Name     string
+	Type     string
+	Optional *bool
+	Readonly bool
+	Nullable bool
+}
+
+// parseTSTag parses the comma separated options of a ts tag, flags can be given a value
+// (optional=false) and commas inside brackets are kept so type=Record<string, number> works
+func parseTSTag(tag string) TSTag {
+	tsTag := TSTag{}
+	if tag == "-" {
+		tsTag.Skip = true
+		return tsTag
+	}
+	for _, option := range splitTagOptions(tag) {
+		key, value, hasValue := strings.Cut(option, "=")
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		flag := true
+		if hasValue {
+			if parsed, err := strconv.ParseBool(value); err == nil {
+				flag = parsed
+			}
+		}
+		switch key {
+		case "name":
+			tsTag.Name = value
+		case "type":
+			tsTag.Type = value
+		case "optional":
+			tsTag.Optional = &flag
+		case "readonly":
+			tsTag.Readonly = flag
+		case "nullable":
+			tsTag.Nullable = flag
+		}
+	}
+	return tsTag
+}
+
+// hasOptionalTag reports whether the name tag of a field has omitempty or omitzero, or whether it
+// has optional:"true"
+func (c *Converter) hasOptionalTag(fieldTag reflect.StructTag) bool {
+	options := strings.Split(c.getNameTag(fieldTag), ",")[1:]
+	return slices.Contains(options, "omitempty") || slices.Contains(options, "omitzero") || fieldTag.Get("optional") == "true"
+}
+
+// isOptionalField reports whether a field is rendered as optional, Optional is set when parsing
+// structs while fields built by hand without it still get it from their tags
+func (c *Converter) isOptionalField(pf ParsedField) bool {
+	if pf.Optional || pf.Tag == "" {
+		return pf.Optional
+	}
+	fieldTag := reflect.StructTag(pf.Tag)
+	if tsTag := parseTSTag(fieldTag.Get("ts")); tsTag.Optional != nil {
+		return *tsTag.Optional
+	}
+	return c.hasOptionalTag(fieldTag)
+}
+
+// getNameTag returns the value of the first of TagKeys present on the field
+func (c *Converter) getNameTag(fieldTag reflect.StructTag) string {
+	tagKeys := c.TagKeys
+	if len(tagKeys) == 0 {
+		tagKeys = []string{"json"}
+	}
+	for _, key := range tagKeys {
+		if value, ok := fieldTag.Lookup(key); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+func splitTagOptions(tag string) []string {
+	options := []string{}
+	depth := 0
+	start := 0
+	for i := 0; i < len(tag); i++ {
+		switch tag[i] {
+		case '<', '[', '{', '(':
+			depth++
+		case '>', ']', '}', ')':
+			if tag[i] == '>' && i > 0 && tag[i-1] == '=' {
+				// arrow of a function type
+				continue
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				options = append(options, tag[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if start < len(tag) {
+		options = append(options, tag[start:])
+	}
+	return options
+}
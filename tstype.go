@@ -0,0 +1,488 @@
+package gos2tsi
+
+import (
+	"go/types"
+	"slices"
+	"strings"
+)
+
+// TypeMapper is a hook for custom go to TS type conversions,
+// it returns ok as false to let the converter handle the type itself
+type TypeMapper func(t types.Type) (tsType string, ok bool)
+
+// MapType registers tsType as the TS type to be emitted for goType by this converter.
+// goType is the fully qualified type as printed by types.TypeString, for ex:
+// "github.com/shopspring/decimal.Decimal" or "example.com/api.Page[example.com/api.User]"
+func (c *Converter) MapType(goType, tsType string) {
+	if c.TypeMappings == nil {
+		c.TypeMappings = map[string]string{}
+	}
+	c.TypeMappings[goType] = tsType
+}
+
+// lookupTypeMapping returns the TS type t is mapped to, or the one its custom encoding gives it
+func (c *Converter) lookupTypeMapping(t types.Type) (string, bool) {
+	if tsType, ok := c.lookupMappedType(t); ok {
+		return tsType, true
+	}
+	return getMarshaledTSType(t)
+}
+
+// lookupMappedType returns the TS type t is mapped to via the TypeMapper or the TypeMappings
+func (c *Converter) lookupMappedType(t types.Type) (string, bool) {
+	if c.TypeMapper != nil {
+		if tsType, ok := c.TypeMapper(t); ok {
+			return tsType, true
+		}
+	}
+	tsType, ok := c.TypeMappings[types.TypeString(t, nil)]
+	if alias, isAlias := t.(*types.Alias); isAlias && !ok {
+		return c.lookupMappedType(types.Unalias(alias))
+	}
+	return tsType, ok
+}
+
+// getMarshaledTSType returns the TS type of named types with their own JSON encoding, time.Time
+// and TextMarshalers are encoded as strings while nothing is known of what json.Marshalers encode
+func getMarshaledTSType(t types.Type) (string, bool) {
+	if _, isNamed := types.Unalias(t).(*types.Named); !isNamed {
+		return "", false
+	}
+	switch {
+	case isTime(t):
+		return "string", true
+	case hasMarshaler(t, isJSONMarshaler):
+		return "unknown", true
+	case hasMarshaler(t, isTextMarshaler):
+		return "string", true
+	}
+	return "", false
+}
+
+// hasMarshaler reports whether t or *t is a marshaler, encoding/json calls the pointer receiver
+// methods of addressable values, ex: the fields of structs encoded via a pointer
+func hasMarshaler(t types.Type, isMarshaler func(types.Type) bool) bool {
+	return isMarshaler(t) || isMarshaler(types.NewPointer(t))
+}
+
+// unwrapFieldType strips the pointers and slices wrapping a field type
+// and returns the element type along with the number of slice levels
+func (c *Converter) unwrapFieldType(t types.Type) (types.Type, int) {
+	isSlice := 0
+	for {
+		if _, ok := c.lookupTypeMapping(t); ok {
+			return t, isSlice
+		}
+		switch item := t.(type) {
+		case *types.Pointer:
+			if c.NullablePointers {
+				return t, isSlice
+			}
+			t = item.Elem()
+		case *types.Slice:
+			if isByteSlice(item) {
+				return t, isSlice
+			}
+			isSlice++
+			t = item.Elem()
+		case *types.Alias:
+			if c.EmitTypeAliases {
+				return t, isSlice
+			}
+			t = types.Unalias(item)
+		default:
+			return t, isSlice
+		}
+	}
+}
+
+// tsType renders a go type as a TS type expression
+func (c *Converter) tsType(t types.Type) string {
+	return c.tsTypeOf(t, "")
+}
+
+// tsTypeOf renders a go type as a TS type expression, inlineName is the name any anonymous
+// struct with in t gets when HoistInlineStructs is set
+func (c *Converter) tsTypeOf(t types.Type, inlineName string) string {
+	if tsType, ok := c.lookupTypeMapping(t); ok {
+		return tsType
+	}
+	switch item := t.(type) {
+	case *types.Pointer:
+		elemType := c.tsTypeOf(item.Elem(), inlineName)
+		if c.NullablePointers && !strings.HasSuffix(elemType, " | null") {
+			return elemType + " | null"
+		}
+		return elemType
+	case *types.Slice:
+		if isByteSlice(item) {
+			// encoding/json encodes []byte as a base64 string
+			return "string"
+		}
+		return c.sliceOf(c.tsTypeOf(item.Elem(), inlineName))
+	case *types.Array:
+		return c.tsTypeFromArray(item, inlineName)
+	case *types.Map:
+		keyType, isEnumKey := c.tsTypeFromMapKey(item.Key())
+		return c.formatMapType(keyType, c.tsTypeOf(item.Elem(), inlineName), isEnumKey)
+	case *types.Struct:
+		return c.tsTypeFromStruct(item, inlineName)
+	case *types.Named:
+		if c.isEnum(item) {
+			return c.tsTypeFromEnum(item)
+		}
+		if basic, isBasic := item.Underlying().(*types.Basic); isBasic {
+			// nothing declares the named type, ex: time.Duration
+			return c.tsType(basic)
+		}
+		if item.TypeArgs().Len() == 0 {
+			return c.getObjectTypeName(item.Obj())
+		}
+		typeArgs := []string{}
+		for i := 0; i < item.TypeArgs().Len(); i++ {
+			typeArgs = append(typeArgs, c.tsType(item.TypeArgs().At(i)))
+		}
+		return c.getObjectTypeName(item.Obj()) + "<" + strings.Join(typeArgs, ", ") + ">"
+	case *types.TypeParam:
+		return item.Obj().Name()
+	case *types.Alias:
+		if c.EmitTypeAliases && item.Obj().Pkg() != nil {
+			return c.tsTypeFromAlias(item)
+		}
+		return c.tsTypeOf(types.Unalias(item), inlineName)
+	}
+	return types.TypeString(t, func(other *types.Package) string { return "" })
+}
+
+// tsTypeFromAlias refers to an alias by its name, adding its declaration to TypeAliases
+func (c *Converter) tsTypeFromAlias(alias *types.Alias) string {
+	origin := alias.Origin()
+	obj := origin.Obj()
+	aliasID := obj.Pkg().Path() + "." + obj.Name()
+	if _, exists := c.TypeAliases[aliasID]; !exists {
+		if c.TypeAliases == nil {
+			c.TypeAliases = map[string]ParsedAlias{}
+		}
+		parsedAlias := ParsedAlias{
+			PackageName: obj.Pkg().Name(),
+			PackgePath:  obj.Pkg().Path(),
+			ID:          aliasID,
+			Name:        obj.Name(),
+		}
+		for i := 0; i < origin.TypeParams().Len(); i++ {
+			typeParam := origin.TypeParams().At(i)
+			formatted := typeParam.Obj().Name()
+			if bound := c.tsTypeFromConstraint(typeParam.Constraint()); bound != "" {
+				formatted += " extends " + bound
+			}
+			parsedAlias.TypeParams = append(parsedAlias.TypeParams, formatted)
+		}
+		// registered before rendering the aliased type as it may refer back to the alias
+		c.TypeAliases[aliasID] = parsedAlias
+		parsedAlias.TSType = c.tsType(origin.Rhs())
+		c.TypeAliases[aliasID] = parsedAlias
+		c.parseReferencedStructs(origin.Rhs(), 0)
+	}
+	if alias.TypeArgs().Len() == 0 {
+		return obj.Name()
+	}
+	typeArgs := []string{}
+	for i := 0; i < alias.TypeArgs().Len(); i++ {
+		typeArgs = append(typeArgs, c.tsType(alias.TypeArgs().At(i)))
+	}
+	return obj.Name() + "<" + strings.Join(typeArgs, ", ") + ">"
+}
+
+// GetTypeAliasString returns the TS declaration of a go type alias, ex: export type Set<T> = {[key: string]: {}}
+func (c *Converter) GetTypeAliasString(pa ParsedAlias) string {
+	name := pa.Name
+	if len(pa.TypeParams) > 0 {
+		name += "<" + strings.Join(pa.TypeParams, ", ") + ">"
+	}
+	return c.endDeclaration(c.getDeclarationPrefix("type") + name + " = " + pa.TSType)
+}
+
+// tsTypeFromArray renders a go array as a TS tuple, ex: [2]float64 as [number, number]
+func (c *Converter) tsTypeFromArray(arr *types.Array, inlineName string) string {
+	if basic, ok := arr.Elem().Underlying().(*types.Basic); ok && basic.Kind() == types.Byte && c.ByteArraysAsString {
+		return "string"
+	}
+	return c.tupleOf(c.tsTypeOf(arr.Elem(), inlineName), arr.Len())
+}
+
+// tupleOf returns a TS tuple of length elements of elemType, or the TS array above MaxTupleLength
+func (c *Converter) tupleOf(elemType string, length int64) string {
+	if c.MaxTupleLength > 0 && length > c.MaxTupleLength {
+		return c.sliceOf(elemType)
+	}
+	elemTypes := make([]string, length)
+	for i := range elemTypes {
+		elemTypes[i] = elemType
+	}
+	if c.Style.ReadonlyArrays {
+		return "readonly [" + strings.Join(elemTypes, ", ") + "]"
+	}
+	return "[" + strings.Join(elemTypes, ", ") + "]"
+}
+
+// getInterfaceName formats the name of ps along with its type parameters, bounded by the TS
+// equivalent of their constraints, ex: Paged<T, N extends number>
+func (c *Converter) getInterfaceName(ps ParsedStruct) string {
+	so, exists := c.structObjects[ps.PackgePath+"."+removeGenericsPartFromStructName(ps.Name)]
+	if !exists {
+		return c.getFormattedStructName(ps)
+	}
+	named, ok := so.obj.Type().(*types.Named)
+	if !ok || named.TypeParams().Len() == 0 {
+		return c.getFormattedStructName(ps)
+	}
+	typeParams := []string{}
+	for i := 0; i < named.TypeParams().Len(); i++ {
+		typeParam := named.TypeParams().At(i)
+		formatted := typeParam.Obj().Name()
+		if bound := c.tsTypeFromConstraint(typeParam.Constraint()); bound != "" {
+			formatted += " extends " + bound
+		}
+		typeParams = append(typeParams, formatted)
+	}
+	return c.getObjectTypeName(named.Obj()) + "<" + strings.Join(typeParams, ", ") + ">"
+}
+
+// tsTypeFromConstraint renders the type set of a constraint as a union of TS types, constraints
+// without a type set (any, comparable, method only interfaces) have no TS bound and return ""
+func (c *Converter) tsTypeFromConstraint(constraint types.Type) string {
+	bounds := []string{}
+	for _, term := range constraintTerms(constraint) {
+		c.parseReferencedStructs(term, 0)
+		bound := c.tsType(term)
+		if !slices.Contains(bounds, bound) {
+			bounds = append(bounds, bound)
+		}
+	}
+	return strings.Join(bounds, " | ")
+}
+
+// constraintTerms flattens the type terms of a constraint interface, tildes are dropped
+func constraintTerms(constraint types.Type) []types.Type {
+	iface, ok := constraint.Underlying().(*types.Interface)
+	if !ok {
+		return []types.Type{constraint}
+	}
+	terms := []types.Type{}
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		switch embedded := iface.EmbeddedType(i).(type) {
+		case *types.Union:
+			for j := 0; j < embedded.Len(); j++ {
+				terms = append(terms, constraintTerms(embedded.Term(j).Type())...)
+			}
+		default:
+			terms = append(terms, constraintTerms(embedded)...)
+		}
+	}
+	return terms
+}
+
+// tsTypeFromStruct renders an anonymous struct as an inline TS object literal type, or when
+// HoistInlineStructs is set adds it to Structs as inlineName and refers to it by that name
+func (c *Converter) tsTypeFromStruct(st *types.Struct, inlineName string) string {
+	fields := c.parseStructFields(st, inlineName)
+	if c.HoistInlineStructs && inlineName != "" && st.NumFields() > 0 {
+		pkg := st.Field(0).Pkg()
+		structID := pkg.Path() + "." + inlineName
+		c.Structs[structID] = ParsedStruct{
+			PackageName: pkg.Name(),
+			PackgePath:  pkg.Path(),
+			ID:          structID,
+			Name:        inlineName,
+			Required:    c.Structs[structID].Required,
+			Fields:      fields,
+		}
+		return inlineName
+	}
+	signatures := []string{}
+	for _, pf := range c.flattenEmbeddedFields(fields) {
+		signatures = append(signatures, c.getFieldSignature(pf))
+	}
+	return c.braces(strings.Join(signatures, "; "))
+}
+
+// tsTypeFromMapKey renders a map key the way encoding/json encodes it, string kinds are used as is
+// and TextMarshalers and integers are formatted as strings, any other key is reported and typed as a
+// string, isEnum reports string enums which can't
+// be used in an index signature, not every value has to be present in the map
+func (c *Converter) tsTypeFromMapKey(key types.Type) (tsType string, isEnum bool) {
+	if c.TypeMapper != nil {
+		if tsType, ok := c.TypeMapper(key); ok {
+			return tsType, false
+		}
+	}
+	key = types.Unalias(key)
+	if _, ok := key.(*types.TypeParam); ok {
+		// whatever it's instantiated with, it's a string once encoded
+		return "string", false
+	}
+	basic, isBasic := key.Underlying().(*types.Basic)
+	if isBasic && basic.Info()&types.IsString != 0 {
+		if named, ok := key.(*types.Named); ok && c.isEnum(named) {
+			return c.tsTypeFromEnum(named), true
+		}
+		return "string", false
+	}
+	if isTextMarshaler(key) {
+		return "string", false
+	}
+	// integers are formatted as strings, the other keys encoding/json rejects are reported by
+	// checkFieldType, ex: float64 or bool, and typed as strings as no object key is anything else
+	return "string", false
+}
+
+func (c *Converter) formatMapType(keyType, valueType string, isEnumKey bool) string {
+	if c.MapsAsRecord && isEnumKey {
+		return "Partial<Record<" + keyType + ", " + valueType + ">>"
+	}
+	if c.MapsAsRecord {
+		return "Record<" + keyType + ", " + valueType + ">"
+	}
+	if isEnumKey {
+		return c.braces("[key in " + keyType + "]?: " + valueType)
+	}
+	return c.braces("[key: " + keyType + "]: " + valueType)
+}
+
+// hasDeclaredConstants reports whether the package declaring named also declares constants of it
+func hasDeclaredConstants(named *types.Named) bool {
+	pkg := named.Obj().Pkg()
+	if pkg == nil {
+		return false
+	}
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		if constant, ok := scope.Lookup(name).(*types.Const); ok && types.Identical(constant.Type(), named) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTextMarshaler reports whether t implements encoding.TextMarshaler with a value receiver
+// which is what encoding/json requires of map keys
+func isTextMarshaler(t types.Type) bool {
+	method, _, _ := types.LookupFieldOrMethod(t, false, nil, "MarshalText")
+	fn, ok := method.(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Params().Len() != 0 || sig.Results().Len() != 2 {
+		return false
+	}
+	return types.TypeString(sig.Results().At(0).Type(), nil) == "[]byte" &&
+		types.TypeString(sig.Results().At(1).Type(), nil) == "error"
+}
+
+// isJSONMarshaler reports whether t implements json.Marshaler with a value receiver
+func isJSONMarshaler(t types.Type) bool {
+	method, _, _ := types.LookupFieldOrMethod(t, false, nil, "MarshalJSON")
+	fn, ok := method.(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := fn.Type().(*types.Signature)
+	return sig.Params().Len() == 0 && sig.Results().Len() == 2 &&
+		types.TypeString(sig.Results().At(0).Type(), nil) == "[]byte" &&
+		types.TypeString(sig.Results().At(1).Type(), nil) == "error"
+}
+
+// isByteSlice reports whether s is a []byte, which encoding/json encodes as a base64 string
+func isByteSlice(s *types.Slice) bool {
+	basic, ok := s.Elem().Underlying().(*types.Basic)
+	return ok && basic.Kind() == types.Byte
+}
+
+// hasTypeParams reports whether t refers to any type parameter
+func hasTypeParams(t types.Type) bool {
+	switch item := types.Unalias(t).(type) {
+	case *types.TypeParam:
+		return true
+	case *types.Pointer:
+		return hasTypeParams(item.Elem())
+	case *types.Slice:
+		return hasTypeParams(item.Elem())
+	case *types.Array:
+		return hasTypeParams(item.Elem())
+	case *types.Map:
+		return hasTypeParams(item.Key()) || hasTypeParams(item.Elem())
+	case *types.Named:
+		for i := 0; i < item.TypeArgs().Len(); i++ {
+			if hasTypeParams(item.TypeArgs().At(i)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// arrayOf returns the TS array type of elemType, wrapping it in parentheses when it is a union
+func arrayOf(elemType string) string {
+	if hasTopLevelUnion(elemType) {
+		return "(" + elemType + ")[]"
+	}
+	return elemType + "[]"
+}
+
+// hasTopLevelUnion reports whether tsType is a union that needs parentheses to be an array element
+func hasTopLevelUnion(tsType string) bool {
+	depth := 0
+	for i, r := range tsType {
+		switch r {
+		case '(', '[', '{', '<':
+			depth++
+		case ')', ']', '}', '>':
+			depth--
+		case '|':
+			if depth == 0 && i > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseReferencedStructs parses the packages of all the named structs used with in t
+// and returns the parsed struct t itself refers to if any
+func (c *Converter) parseReferencedStructs(t types.Type, isSlice int) ParsedStruct {
+	if _, ok := c.lookupTypeMapping(t); ok {
+		return ParsedStruct{}
+	}
+	switch item := t.(type) {
+	case *types.Alias:
+		return c.parseReferencedStructs(types.Unalias(item), isSlice)
+	case *types.Pointer:
+		return c.parseReferencedStructs(item.Elem(), isSlice)
+	case *types.Slice:
+		c.parseReferencedStructs(item.Elem(), 0)
+	case *types.Array:
+		c.parseReferencedStructs(item.Elem(), 0)
+	case *types.Map:
+		c.parseReferencedStructs(item.Key(), 0)
+		c.parseReferencedStructs(item.Elem(), 0)
+	case *types.Named:
+		for i := 0; i < item.TypeArgs().Len(); i++ {
+			c.parseReferencedStructs(item.TypeArgs().At(i), 0)
+		}
+		if _, ok := item.Underlying().(*types.Interface); ok {
+			c.parseUnion(item)
+		}
+		if _, ok := item.Underlying().(*types.Struct); ok && item.Obj().Pkg() != nil {
+			structName := item.Obj().Name()
+			if item.TypeArgs().Len() > 0 && !hasTypeParams(item) {
+				// same format reflect names instantiated types with so the type arguments become GenericPopulations
+				structName = strings.ReplaceAll(strings.TrimPrefix(types.TypeString(item, nil), item.Obj().Pkg().Path()+"."), ", ", ",")
+			}
+			refStruct := c.parseStructsInPackage(item.Obj().Pkg().Path(), structName, isSlice)
+			refStruct.IsSlice = isSlice
+			return refStruct
+		}
+	}
+	return ParsedStruct{}
+}
@@ -0,0 +1,200 @@
+package gos2tsi
+
+import (
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// ParsedUnion is a go interface emitted as a union of the structs implementing it
+type ParsedUnion struct {
+	PackageName string
+	PackgePath  string
+	ID          string
+	Name        string
+	// Discriminator is the json name of the field telling the members apart, it gets the literal
+	// type set via //gos2tsi:variant value=... on the member, members without one keep their go type
+	Discriminator string
+	Members       []ParsedStruct
+	// Required is set for the unions the roots refer to, the ones that get output
+	Required bool
+}
+
+type discriminatorField struct {
+	TSName string
+	Value  string
+}
+
+// getUnionDiscriminator reports whether the interface interfaceID is declared as a union, via
+// UnionInterfaces or a //gos2tsi:union directive, and the json name of its discriminator field
+func (c *Converter) getUnionDiscriminator(interfaceID string) (string, bool) {
+	if discriminator, ok := c.UnionInterfaces[interfaceID]; ok {
+		return discriminator, true
+	}
+	if args, ok := c.getDirective(interfaceID, "union"); ok {
+		return args["discriminator"], true
+	}
+	return "", false
+}
+
+// parseUnion registers named in Unions if it is declared as a union and collects its members
+func (c *Converter) parseUnion(named *types.Named) {
+	obj := named.Obj()
+	if obj.Pkg() == nil {
+		return
+	}
+	pkgPath := obj.Pkg().Path()
+	unionID := pkgPath + "." + obj.Name()
+	if _, exists := c.Unions[unionID]; exists {
+		return
+	}
+	if _, exists := c.AlreadyParsedPackage[pkgPath]; !exists {
+		c.parsePackage(pkgPath)
+	}
+	discriminator, isUnion := c.getUnionDiscriminator(unionID)
+	_, isInterface := named.Underlying().(*types.Interface)
+	if !isUnion || !isInterface {
+		return
+	}
+	if c.Unions == nil {
+		c.Unions = map[string]ParsedUnion{}
+	}
+	parsedUnion := ParsedUnion{
+		PackageName:   obj.Pkg().Name(),
+		PackgePath:    pkgPath,
+		ID:            unionID,
+		Name:          obj.Name(),
+		Discriminator: discriminator,
+	}
+	c.Unions[unionID] = parsedUnion
+	c.updateUnionMembers(unionID)
+}
+
+// updateUnionMembers collects the structs implementing the union among all the loaded packages,
+// it runs again whenever roots are added so the members don't depend on the order packages load in
+func (c *Converter) updateUnionMembers(unionID string) {
+	parsedUnion := c.Unions[unionID]
+	pkg, exists := c.loadedPackages[parsedUnion.PackgePath]
+	if !exists {
+		return
+	}
+	obj, ok := pkg.Types.Scope().Lookup(parsedUnion.Name).(*types.TypeName)
+	if !ok {
+		return
+	}
+	iface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return
+	}
+	memberIDs := []string{}
+	for structID, so := range c.structObjects {
+		if implementsInterface(so.obj.Type(), iface) {
+			memberIDs = append(memberIDs, structID)
+		}
+	}
+	// declaration order
+	sort.Slice(memberIDs, func(i, j int) bool {
+		a, b := c.structObjects[memberIDs[i]], c.structObjects[memberIDs[j]]
+		if a.pkg.PkgPath != b.pkg.PkgPath {
+			return a.pkg.PkgPath < b.pkg.PkgPath
+		}
+		return a.obj.Pos() < b.obj.Pos()
+	})
+	parsedUnion.Members = nil
+	for _, structID := range memberIDs {
+		so := c.structObjects[structID]
+		if args, ok := c.getDirective(structID, "variant"); ok && args["value"] != "" && parsedUnion.Discriminator != "" {
+			if c.discriminators == nil {
+				c.discriminators = map[string]discriminatorField{}
+			}
+			c.discriminators[structID] = discriminatorField{TSName: parsedUnion.Discriminator, Value: args["value"]}
+		}
+		member := c.parseStructsInPackage(strings.TrimSuffix(structID, "."+so.obj.Name()), so.obj.Name(), 0)
+		if _, hasVariant := c.discriminators[structID]; parsedUnion.Discriminator != "" && !hasVariant {
+			c.reportMissingVariant(parsedUnion, structID)
+		}
+		parsedUnion.Members = append(parsedUnion.Members, member)
+	}
+	c.Unions[unionID] = parsedUnion
+}
+
+// reportMissingVariant records a diagnostic for a member of a discriminated union without a
+// //gos2tsi:variant value, its discriminator keeps the go type of the field so the union doesn't narrow
+func (c *Converter) reportMissingVariant(pu ParsedUnion, structID string) {
+	so := c.structObjects[structID]
+	diagnostic := Diagnostic{
+		StructID: structID,
+		Struct:   so.obj.Name(),
+		Field:    pu.Discriminator,
+		Message:  "member of the union " + pu.Name + " without a //gos2tsi:variant value for its discriminator",
+	}
+	pos := so.obj.Pos()
+	for _, pf := range c.Structs[structID].Fields {
+		if pf.TSName == pu.Discriminator && pf.Var != nil {
+			diagnostic.Field = pf.Var.Name()
+			diagnostic.GoType = types.TypeString(pf.Var.Type(), nil)
+			pos = pf.Var.Pos()
+		}
+	}
+	if c.fset != nil {
+		diagnostic.Pos = c.fset.Position(pos)
+	}
+	c.addDiagnostic(diagnostic)
+}
+
+// narrowDiscriminator narrows the discriminator field of the union member ps down to the literal
+// type of its variant, the other fields are returned as is
+func (c *Converter) narrowDiscriminator(ps ParsedStruct, pf ParsedField) ParsedField {
+	if discriminator, ok := c.getDiscriminator(ps, pf); ok {
+		pf.TSType = c.quote(discriminator.Value)
+		pf.IsSlice = 0
+	}
+	return pf
+}
+
+// getDiscriminator returns the discriminator of the union member ps if pf is its discriminator field
+func (c *Converter) getDiscriminator(ps ParsedStruct, pf ParsedField) (discriminatorField, bool) {
+	discriminator, ok := c.discriminators[ps.PackgePath+"."+removeGenericsPartFromStructName(ps.Name)]
+	return discriminator, ok && discriminator.TSName == pf.TSName
+}
+
+// implementsInterface is types.Implements for types coming from different package loads,
+// methods are matched by their name (and package, if unexported) and printed signature
+func implementsInterface(t types.Type, iface *types.Interface) bool {
+	if iface.NumMethods() == 0 {
+		return false
+	}
+	methodSet := types.NewMethodSet(types.NewPointer(t))
+	for i := 0; i < iface.NumMethods(); i++ {
+		method := iface.Method(i)
+		found := false
+		for j := 0; j < methodSet.Len(); j++ {
+			candidate := methodSet.At(j).Obj()
+			if candidate.Name() != method.Name() {
+				continue
+			}
+			if !method.Exported() && candidate.Pkg().Path() != method.Pkg().Path() {
+				continue
+			}
+			found = types.TypeString(candidate.Type(), nil) == types.TypeString(method.Type(), nil)
+			break
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// GetUnionAsTypeString returns the TS union of the structs implementing an interface,
+// ex: export type Event = UserCreated | OrderPaid
+func (c *Converter) GetUnionAsTypeString(pu ParsedUnion) string {
+	members := []string{}
+	for _, member := range pu.Members {
+		members = append(members, c.getFormattedStructName(member))
+	}
+	if len(members) == 0 {
+		members = append(members, "never")
+	}
+	return c.endDeclaration(c.getDeclarationPrefix("type") + c.getTypeName(pu.PackgePath, pu.Name) + " = " + strings.Join(members, " | "))
+}